@@ -1,6 +1,9 @@
 package channel
 
 import (
+	"atlas-transports/kafka/message"
+	channel2 "atlas-transports/kafka/message/channel"
+	"atlas-transports/kafka/producer"
 	"context"
 	"github.com/Chronicle20/atlas-constants/channel"
 	"github.com/Chronicle20/atlas-constants/world"
@@ -12,12 +15,16 @@ type Processor interface {
 	Register(worldId world.Id, channelId channel.Id) error
 	Unregister(worldId world.Id, channelId channel.Id) error
 	GetAll() []channel.Model
+	RequestStatus(mb *message.Buffer) error
+	RequestStatusAndEmit() error
+	Bootstrap() error
 }
 
 type ProcessorImpl struct {
 	l   logrus.FieldLogger
 	ctx context.Context
 	t   tenant.Model
+	p   producer.Provider
 }
 
 func NewProcessor(l logrus.FieldLogger, ctx context.Context) Processor {
@@ -25,6 +32,7 @@ func NewProcessor(l logrus.FieldLogger, ctx context.Context) Processor {
 		l:   l,
 		ctx: ctx,
 		t:   tenant.MustFromContext(ctx),
+		p:   producer.ProviderImpl(l)(ctx),
 	}
 }
 
@@ -41,3 +49,24 @@ func (p *ProcessorImpl) Unregister(worldId world.Id, channelId channel.Id) error
 func (p *ProcessorImpl) GetAll() []channel.Model {
 	return getRegistry().GetAll(p.t.Id())
 }
+
+// RequestStatus asks every running channel of the tenant to announce itself. Channels reply with a STARTED status
+// event, which registers them.
+func (p *ProcessorImpl) RequestStatus(mb *message.Buffer) error {
+	return mb.Put(channel2.EnvCommandTopic, StatusRequestCommandProvider())
+}
+
+func (p *ProcessorImpl) RequestStatusAndEmit() error {
+	return message.Emit(p.p)(p.RequestStatus)
+}
+
+// Bootstrap rebuilds the tenant's channel registry from scratch, from the replies to a status request.
+func (p *ProcessorImpl) Bootstrap() error {
+	p.l.Debugf("Requesting channel status for tenant [%s].", p.t.Id())
+	getRegistry().Clear(p.t.Id())
+	err := p.RequestStatusAndEmit()
+	if err != nil {
+		p.l.WithError(err).Errorf("Unable to request channel status for tenant [%s].", p.t.Id())
+	}
+	return err
+}
@@ -0,0 +1,16 @@
+package channel
+
+import (
+	channel2 "atlas-transports/kafka/message/channel"
+	"github.com/Chronicle20/atlas-kafka/producer"
+	"github.com/Chronicle20/atlas-model/model"
+	"github.com/segmentio/kafka-go"
+)
+
+func StatusRequestCommandProvider() model.Provider[[]kafka.Message] {
+	key := producer.CreateKey(0)
+	value := &channel2.StatusCommand{
+		Type: channel2.CommandTypeStatusRequest,
+	}
+	return producer.SingleMessageProvider(key, value)
+}
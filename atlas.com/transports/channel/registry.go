@@ -70,6 +70,13 @@ func (r *Registry) Remove(tenantId uuid.UUID, worldId world.Id, id channel.Id) {
 	}
 }
 
+// Clear removes all models of the given tenant
+func (r *Registry) Clear(tenantId uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.store, tenantId)
+}
+
 // GetAll returns a copy of all models for a given tenant
 func (r *Registry) GetAll(tenantId uuid.UUID) []channel.Model {
 	r.mu.RLock()
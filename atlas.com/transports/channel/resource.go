@@ -0,0 +1,35 @@
+package channel
+
+import (
+	"atlas-transports/rest"
+	"github.com/Chronicle20/atlas-model/model"
+	"github.com/Chronicle20/atlas-rest/server"
+	"github.com/gorilla/mux"
+	"github.com/jtumidanski/api2go/jsonapi"
+	"github.com/sirupsen/logrus"
+	"net/http"
+)
+
+// InitResource registers the channel routes with the router
+func InitResource(si jsonapi.ServerInformation) server.RouteInitializer {
+	return func(r *mux.Router, l logrus.FieldLogger) {
+		registerHandler := rest.RegisterHandler(l)(si)
+		r.HandleFunc("/transports/channels", registerHandler("get_all_channels", GetAllChannelsHandler)).Methods(http.MethodGet)
+	}
+}
+
+// GetAllChannelsHandler returns a handler for the GET /transports/channels endpoint
+func GetAllChannelsHandler(d *rest.HandlerDependency, c *rest.HandlerContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rm, err := model.SliceMap(Transform)(model.FixedProvider(NewProcessor(d.Logger(), d.Context()).GetAll()))(model.ParallelMap())()
+		if err != nil {
+			d.Logger().WithError(err).Errorln("Error retrieving channels")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		query := r.URL.Query()
+		queryParams := jsonapi.ParseQueryFields(&query)
+		server.MarshalResponse[[]RestModel](d.Logger())(w)(c.ServerInformation())(queryParams)(rm)
+	}
+}
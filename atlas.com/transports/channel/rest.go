@@ -0,0 +1,35 @@
+package channel
+
+import (
+	"fmt"
+	"github.com/Chronicle20/atlas-constants/channel"
+	"github.com/Chronicle20/atlas-constants/world"
+)
+
+// RestModel is the JSON:API resource for a registered channel
+type RestModel struct {
+	Id        string     `json:"-"`
+	WorldId   world.Id   `json:"worldId"`
+	ChannelId channel.Id `json:"channelId"`
+}
+
+func (r RestModel) GetName() string {
+	return "channels"
+}
+
+func (r RestModel) GetID() string {
+	return r.Id
+}
+
+func (r *RestModel) SetID(idStr string) error {
+	r.Id = idStr
+	return nil
+}
+
+func Transform(m channel.Model) (RestModel, error) {
+	return RestModel{
+		Id:        fmt.Sprintf("%d:%d", m.WorldId(), m.Id()),
+		WorldId:   m.WorldId(),
+		ChannelId: m.Id(),
+	}, nil
+}
@@ -0,0 +1,26 @@
+package character
+
+import _map "github.com/Chronicle20/atlas-constants/map"
+
+type Model struct {
+	id    uint32
+	mapId _map.Id
+	x     int16
+	y     int16
+}
+
+func (m Model) Id() uint32 {
+	return m.id
+}
+
+func (m Model) MapId() _map.Id {
+	return m.mapId
+}
+
+func (m Model) X() int16 {
+	return m.x
+}
+
+func (m Model) Y() int16 {
+	return m.y
+}
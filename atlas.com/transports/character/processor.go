@@ -9,14 +9,22 @@ import (
 	"errors"
 	"github.com/Chronicle20/atlas-constants/field"
 	"github.com/Chronicle20/atlas-model/model"
+	"github.com/Chronicle20/atlas-rest/requests"
 	tenant "github.com/Chronicle20/atlas-tenant"
 	"github.com/sirupsen/logrus"
 )
 
+// byIdsBatchSize bounds the number of characters requested at once, keeping request URLs short.
+const byIdsBatchSize = 100
+
 type Processor interface {
+	ByIdProvider(characterId uint32) model.Provider[Model]
+	ByIdsProvider(characterIds []uint32) model.Provider[[]Model]
 	WarpRandom(mb *message.Buffer) func(characterId uint32) func(fieldId field.Id) error
 	WarpRandomAndEmit(characterId uint32, fieldId field.Id) error
 	WarpToPortal(mb *message.Buffer) func(characterId uint32, fieldId field.Id, pp model.Provider[uint32]) error
+	IdempotentWarpRandom(mb *message.Buffer) func(key string) func(characterId uint32) func(fieldId field.Id) error
+	IdempotentWarpToPortal(mb *message.Buffer) func(key string, characterId uint32, fieldId field.Id, pp model.Provider[uint32]) error
 }
 
 type ProcessorImpl struct {
@@ -37,18 +45,31 @@ func NewProcessor(l logrus.FieldLogger, ctx context.Context) Processor {
 	}
 }
 
-func (p *ProcessorImpl) WarpRandom(mb *message.Buffer) func(characterId uint32) func(fieldId field.Id) error {
-	return func(characterId uint32) func(fieldId field.Id) error {
-		return func(fieldId field.Id) error {
-			f, ok := field.FromId(fieldId)
-			if !ok {
-				return errors.New("invalid field")
+func (p *ProcessorImpl) ByIdProvider(characterId uint32) model.Provider[Model] {
+	return requests.Provider[RestModel, Model](p.l, p.ctx)(requestById(characterId), Extract)
+}
+
+// ByIdsProvider returns the characters with the given ids, requested in batches of at most byIdsBatchSize. Characters
+// which are not found are left out.
+func (p *ProcessorImpl) ByIdsProvider(characterIds []uint32) model.Provider[[]Model] {
+	return func() ([]Model, error) {
+		results := make([]Model, 0, len(characterIds))
+		for start := 0; start < len(characterIds); start += byIdsBatchSize {
+			batch := characterIds[start:min(start+byIdsBatchSize, len(characterIds))]
+			cs, err := requests.SliceProvider[RestModel, Model](p.l, p.ctx)(requestByIds(batch), Extract, model.Filters[Model]())()
+			if err != nil {
+				return nil, err
 			}
-			return p.WarpToPortal(mb)(characterId, fieldId, p.pp.RandomSpawnPointIdProvider(f.MapId()))
+			results = append(results, cs...)
 		}
+		return results, nil
 	}
 }
 
+func (p *ProcessorImpl) WarpRandom(mb *message.Buffer) func(characterId uint32) func(fieldId field.Id) error {
+	return p.IdempotentWarpRandom(mb)("")
+}
+
 func (p *ProcessorImpl) WarpRandomAndEmit(characterId uint32, fieldId field.Id) error {
 	return message.Emit(p.p)(func(mb *message.Buffer) error {
 		return p.WarpRandom(mb)(characterId)(fieldId)
@@ -57,14 +78,41 @@ func (p *ProcessorImpl) WarpRandomAndEmit(characterId uint32, fieldId field.Id)
 
 func (p *ProcessorImpl) WarpToPortal(mb *message.Buffer) func(characterId uint32, fieldId field.Id, pp model.Provider[uint32]) error {
 	return func(characterId uint32, fieldId field.Id, pp model.Provider[uint32]) error {
+		return p.IdempotentWarpToPortal(mb)("", characterId, fieldId, pp)
+	}
+}
+
+// IdempotentWarpRandom warps the character to a random spawn point, unless a warp with the same key was already issued.
+func (p *ProcessorImpl) IdempotentWarpRandom(mb *message.Buffer) func(key string) func(characterId uint32) func(fieldId field.Id) error {
+	return func(key string) func(characterId uint32) func(fieldId field.Id) error {
+		return func(characterId uint32) func(fieldId field.Id) error {
+			return func(fieldId field.Id) error {
+				f, ok := field.FromId(fieldId)
+				if !ok {
+					return errors.New("invalid field")
+				}
+				return p.IdempotentWarpToPortal(mb)(key, characterId, fieldId, p.pp.RandomSpawnPointIdProvider(f.MapId()))
+			}
+		}
+	}
+}
+
+// IdempotentWarpToPortal warps the character to the provided portal, unless a warp with the same key was already issued.
+// An empty key disables the check.
+func (p *ProcessorImpl) IdempotentWarpToPortal(mb *message.Buffer) func(key string, characterId uint32, fieldId field.Id, pp model.Provider[uint32]) error {
+	return func(key string, characterId uint32, fieldId field.Id, pp model.Provider[uint32]) error {
 		f, ok := field.FromId(fieldId)
 		if !ok {
 			return errors.New("invalid field")
 		}
+		if key != "" && producer.IdempotencyKeyPublished(p.t.Id(), key) {
+			p.l.Debugf("Warp [%s] for character [%d] was already issued, skipping.", key, characterId)
+			return nil
+		}
 		portalId, err := pp()
 		if err != nil {
 			return err
 		}
-		return mb.Put(character2.EnvCommandTopic, ChangeMapProvider(f.WorldId(), f.ChannelId(), characterId, f.MapId(), portalId))
+		return mb.Put(character2.EnvCommandTopic, ChangeMapProvider(f.WorldId(), f.ChannelId(), characterId, f.MapId(), portalId, key))
 	}
 }
@@ -2,6 +2,7 @@ package character
 
 import (
 	character2 "atlas-transports/kafka/message/character"
+	producer2 "atlas-transports/kafka/producer"
 	"github.com/Chronicle20/atlas-constants/channel"
 	_map "github.com/Chronicle20/atlas-constants/map"
 	"github.com/Chronicle20/atlas-constants/world"
@@ -10,7 +11,7 @@ import (
 	"github.com/segmentio/kafka-go"
 )
 
-func ChangeMapProvider(worldId world.Id, channelId channel.Id, characterId uint32, mapId _map.Id, portalId uint32) model.Provider[[]kafka.Message] {
+func ChangeMapProvider(worldId world.Id, channelId channel.Id, characterId uint32, mapId _map.Id, portalId uint32, idempotencyKey string) model.Provider[[]kafka.Message] {
 	key := producer.CreateKey(int(characterId))
 	value := &character2.Command[character2.ChangeMapBody]{
 		WorldId:     worldId,
@@ -22,5 +23,5 @@ func ChangeMapProvider(worldId world.Id, channelId channel.Id, characterId uint3
 			PortalId:  portalId,
 		},
 	}
-	return producer.SingleMessageProvider(key, value)
+	return producer2.WithIdempotencyKey(idempotencyKey)(producer.SingleMessageProvider(key, value))
 }
@@ -0,0 +1,30 @@
+package character
+
+import (
+	"atlas-transports/rest"
+	"fmt"
+	"github.com/Chronicle20/atlas-rest/requests"
+	"strconv"
+	"strings"
+)
+
+const (
+	characterResource  = "characters/%d"
+	charactersResource = "characters?filter[ids]=%s"
+)
+
+func getBaseRequest() string {
+	return requests.RootUrl("CHARACTERS")
+}
+
+func requestById(characterId uint32) requests.Request[RestModel] {
+	return rest.MakeGetRequest[RestModel](fmt.Sprintf(getBaseRequest()+characterResource, characterId))
+}
+
+func requestByIds(characterIds []uint32) requests.Request[[]RestModel] {
+	ids := make([]string, 0, len(characterIds))
+	for _, id := range characterIds {
+		ids = append(ids, strconv.FormatUint(uint64(id), 10))
+	}
+	return rest.MakeGetRequest[[]RestModel](fmt.Sprintf(getBaseRequest()+charactersResource, strings.Join(ids, ",")))
+}
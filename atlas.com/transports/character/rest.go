@@ -0,0 +1,39 @@
+package character
+
+import (
+	_map "github.com/Chronicle20/atlas-constants/map"
+	"strconv"
+)
+
+type RestModel struct {
+	Id    string  `json:"-"`
+	MapId _map.Id `json:"mapId"`
+	X     int16   `json:"x"`
+	Y     int16   `json:"y"`
+}
+
+func (r RestModel) GetName() string {
+	return "characters"
+}
+
+func (r RestModel) GetID() string {
+	return r.Id
+}
+
+func (r *RestModel) SetID(id string) error {
+	r.Id = id
+	return nil
+}
+
+func Extract(rm RestModel) (Model, error) {
+	id, err := strconv.ParseUint(rm.Id, 10, 32)
+	if err != nil {
+		return Model{}, err
+	}
+	return Model{
+		id:    uint32(id),
+		mapId: rm.MapId,
+		x:     rm.X,
+		y:     rm.Y,
+	}, nil
+}
@@ -0,0 +1,45 @@
+package clock
+
+import "time"
+
+// Clock is the source of time used to drive route schedules.
+type Clock interface {
+	// Now returns the current time of the clock.
+	Now() time.Time
+	// After waits for the duration to elapse on the clock, then sends the current time of the clock.
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+// System returns the wall clock.
+func System() Clock {
+	return systemClock{}
+}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+type fixedClock struct {
+	now time.Time
+}
+
+// Fixed returns a clock which is stopped at the given instant. Waits on it complete immediately.
+func Fixed(now time.Time) Clock {
+	return fixedClock{now: now}
+}
+
+func (c fixedClock) Now() time.Time {
+	return c.now
+}
+
+func (c fixedClock) After(time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
@@ -0,0 +1,37 @@
+package clock
+
+import "time"
+
+// Model is the clock of a tenant. It runs at rate times the speed of the wall clock, and reads virtualAt at the wall
+// clock instant realAt.
+type Model struct {
+	realAt    time.Time
+	virtualAt time.Time
+	rate      float64
+}
+
+// NewModel creates a clock which reads at at the wall clock instant now, and runs at rate times its speed.
+func NewModel(now time.Time, at time.Time, rate float64) Model {
+	return Model{realAt: now, virtualAt: at, rate: rate}
+}
+
+// At returns the reading of the clock at the wall clock instant now.
+func (m Model) At(now time.Time) time.Time {
+	elapsed := now.Sub(m.realAt)
+	return m.virtualAt.Add(time.Duration(float64(elapsed) * m.rate))
+}
+
+// Real returns the wall clock time it takes for the duration to elapse on the clock.
+func (m Model) Real(d time.Duration) time.Duration {
+	return time.Duration(float64(d) / m.rate)
+}
+
+// Rate returns the speed of the clock relative to the wall clock
+func (m Model) Rate() float64 {
+	return m.rate
+}
+
+// Offset returns how far the clock is ahead of the wall clock at the wall clock instant now
+func (m Model) Offset(now time.Time) time.Duration {
+	return m.At(now).Sub(now)
+}
@@ -0,0 +1,41 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModel_At(t *testing.T) {
+	now := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := time.Date(2023, 1, 1, 6, 0, 0, 0, time.UTC)
+	m := NewModel(now, at, 60)
+
+	assert.Equal(t, at, m.At(now))
+	assert.Equal(t, at.Add(time.Hour), m.At(now.Add(time.Minute)))
+	assert.Equal(t, time.Second, m.Real(time.Minute))
+	assert.Equal(t, -6*time.Hour, m.Offset(now))
+}
+
+func TestRegistry_UpdateContinuesFromReading(t *testing.T) {
+	tenantId := uuid.New()
+	now := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	r := getRegistry()
+
+	_, ok := r.Get(tenantId, now)
+	assert.False(t, ok)
+
+	at := time.Date(2023, 1, 1, 8, 0, 0, 0, time.UTC)
+	r.Update(tenantId, at, 0, now)
+	r.Update(tenantId, time.Time{}, 10, now.Add(time.Minute))
+	m, ok := r.Get(tenantId, now)
+	assert.True(t, ok)
+	assert.Equal(t, at.Add(time.Minute), m.At(now.Add(time.Minute)))
+	assert.Equal(t, at.Add(11*time.Minute), m.At(now.Add(2*time.Minute)))
+
+	r.Reset(tenantId)
+	_, ok = r.Get(tenantId, now)
+	assert.False(t, ok)
+}
@@ -0,0 +1,83 @@
+package clock
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrControlDisabled is returned when the clock of a tenant is changed while clock control is not enabled.
+var ErrControlDisabled = errors.New("clock control is disabled")
+
+// ErrInvalidRate is returned when the clock of a tenant is set to run at a negative or non-finite speed.
+var ErrInvalidRate = errors.New("clock rate must be positive")
+
+type Processor interface {
+	Clock() Clock
+	Get() (Model, bool)
+	Update(at time.Time, rate float64) (Model, error)
+	Reset() error
+}
+
+type ProcessorImpl struct {
+	l   logrus.FieldLogger
+	ctx context.Context
+	t   tenant.Model
+}
+
+func NewProcessor(l logrus.FieldLogger, ctx context.Context) Processor {
+	return &ProcessorImpl{
+		l:   l,
+		ctx: ctx,
+		t:   tenant.MustFromContext(ctx),
+	}
+}
+
+// Clock returns the clock of the tenant.
+func (p *ProcessorImpl) Clock() Clock {
+	return ForTenant(p.t.Id())
+}
+
+// Get returns the clock of the tenant, and whether it deviates from the wall clock.
+func (p *ProcessorImpl) Get() (Model, bool) {
+	return getRegistry().Get(p.t.Id(), time.Now())
+}
+
+// Update moves the clock of the tenant to at and runs it at rate, as a single change. Both are validated before either
+// is applied. A zero at or rate leaves that part of the clock unchanged.
+func (p *ProcessorImpl) Update(at time.Time, rate float64) (Model, error) {
+	if !controlEnabled() {
+		return Model{}, ErrControlDisabled
+	}
+	if rate < 0 || math.IsNaN(rate) || math.IsInf(rate, 0) {
+		return Model{}, ErrInvalidRate
+	}
+	m := getRegistry().Update(p.t.Id(), at, rate, time.Now())
+	p.l.Warnf("Clock of tenant [%s] now reads [%s] and runs at [%gx] speed.", p.t.Id(), m.At(time.Now()).Format(time.RFC3339), m.Rate())
+	return m, nil
+}
+
+// Reset returns the clock of the tenant to the wall clock.
+func (p *ProcessorImpl) Reset() error {
+	if !controlEnabled() {
+		return ErrControlDisabled
+	}
+	getRegistry().Reset(p.t.Id())
+	p.l.Infof("Clock of tenant [%s] reset to the wall clock.", p.t.Id())
+	return nil
+}
+
+// controlEnabled reports whether tenant clocks may be changed. It must only be enabled on staging deployments.
+func controlEnabled() bool {
+	if val, ok := os.LookupEnv("CLOCK_CONTROL_ENABLED"); ok {
+		enabled, err := strconv.ParseBool(val)
+		return err == nil && enabled
+	}
+	return false
+}
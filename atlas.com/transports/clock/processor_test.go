@@ -0,0 +1,35 @@
+package clock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessor_UpdateValidatesBeforeApplying(t *testing.T) {
+	t.Setenv("CLOCK_CONTROL_ENABLED", "true")
+	l, _ := test.NewNullLogger()
+	ten, _ := tenant.Register(uuid.New(), "GMS", 83, 1)
+	p := NewProcessor(l, tenant.WithContext(context.Background(), ten))
+	at := time.Date(2023, 1, 1, 6, 0, 0, 0, time.UTC)
+
+	_, err := p.Update(at, -1)
+	assert.ErrorIs(t, err, ErrInvalidRate)
+	_, deviates := p.Get()
+	assert.False(t, deviates)
+
+	m, err := p.Update(at, 60)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(60), m.Rate())
+	assert.WithinDuration(t, at, m.At(time.Now()), time.Minute)
+
+	// A zero rate keeps the clock's speed
+	m, err = p.Update(at.Add(time.Hour), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(60), m.Rate())
+}
@@ -0,0 +1,88 @@
+package clock
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Registry holds the clocks of tenants which no longer follow the wall clock.
+type Registry struct {
+	mu     sync.RWMutex
+	clocks map[uuid.UUID]Model
+}
+
+var registry *Registry
+var registryOnce sync.Once
+
+func getRegistry() *Registry {
+	registryOnce.Do(func() {
+		registry = &Registry{
+			clocks: make(map[uuid.UUID]Model),
+		}
+	})
+	return registry
+}
+
+// Get returns the clock of the tenant, and whether it deviates from the wall clock.
+func (r *Registry) Get(tenantId uuid.UUID, now time.Time) (Model, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if m, ok := r.clocks[tenantId]; ok {
+		return m, true
+	}
+	return NewModel(now, now, 1), false
+}
+
+// Update moves the tenant's clock to at and changes its speed to rate, continuing from its current reading and speed
+// for whichever of the two is zero.
+func (r *Registry) Update(tenantId uuid.UUID, at time.Time, rate float64, now time.Time) Model {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, ok := r.clocks[tenantId]
+	if !ok {
+		current = NewModel(now, now, 1)
+	}
+	if at.IsZero() {
+		at = current.At(now)
+	}
+	if rate == 0 {
+		rate = current.Rate()
+	}
+	m := NewModel(now, at, rate)
+	r.clocks[tenantId] = m
+	return m
+}
+
+// Reset returns the tenant's clock to the wall clock.
+func (r *Registry) Reset(tenantId uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.clocks, tenantId)
+}
+
+type tenantClock struct {
+	tenantId uuid.UUID
+}
+
+// ForTenant returns the clock of the tenant. Changes made to the tenant's clock apply to it immediately.
+func ForTenant(tenantId uuid.UUID) Clock {
+	return tenantClock{tenantId: tenantId}
+}
+
+func (c tenantClock) Now() time.Time {
+	now := time.Now()
+	m, _ := getRegistry().Get(c.tenantId, now)
+	return m.At(now)
+}
+
+func (c tenantClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	m, _ := getRegistry().Get(c.tenantId, time.Now())
+	go func() {
+		<-time.After(m.Real(d))
+		ch <- c.Now()
+	}()
+	return ch
+}
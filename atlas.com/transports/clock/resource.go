@@ -0,0 +1,77 @@
+package clock
+
+import (
+	"atlas-transports/rest"
+	"errors"
+	"github.com/Chronicle20/atlas-rest/server"
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/gorilla/mux"
+	"github.com/jtumidanski/api2go/jsonapi"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"time"
+)
+
+// InitResource registers the clock routes with the router
+func InitResource(si jsonapi.ServerInformation) server.RouteInitializer {
+	return func(r *mux.Router, l logrus.FieldLogger) {
+		registerHandler := rest.RegisterHandler(l)(si)
+		registerInputHandler := rest.RegisterInputHandler[RestModel](l)(si)
+		r.HandleFunc("/transports/clock", registerHandler("get_clock", GetClockHandler)).Methods(http.MethodGet)
+		r.HandleFunc("/transports/clock", registerInputHandler("update_clock", UpdateClockHandler)).Methods(http.MethodPatch)
+		r.HandleFunc("/transports/clock", registerHandler("reset_clock", ResetClockHandler)).Methods(http.MethodDelete)
+	}
+}
+
+// GetClockHandler returns a handler for the GET /transports/clock endpoint
+func GetClockHandler(d *rest.HandlerDependency, c *rest.HandlerContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		respond(d, c, w, r)
+	}
+}
+
+// UpdateClockHandler returns a handler for the PATCH /transports/clock endpoint. The clock jumps to now, if given, and
+// then runs at rate, if given. The input is validated as a whole, so an invalid rate does not leave the clock moved.
+func UpdateClockHandler(d *rest.HandlerDependency, c *rest.HandlerContext, input RestModel) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_, err := NewProcessor(d.Logger(), d.Context()).Update(input.Now, input.Rate)
+		if err != nil {
+			d.Logger().WithError(err).Errorln("Error updating clock")
+			w.WriteHeader(statusOf(err))
+			return
+		}
+		respond(d, c, w, r)
+	}
+}
+
+// ResetClockHandler returns a handler for the DELETE /transports/clock endpoint
+func ResetClockHandler(d *rest.HandlerDependency, _ *rest.HandlerContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := NewProcessor(d.Logger(), d.Context()).Reset()
+		if err != nil {
+			d.Logger().WithError(err).Errorln("Error resetting clock")
+			w.WriteHeader(statusOf(err))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func respond(d *rest.HandlerDependency, c *rest.HandlerContext, w http.ResponseWriter, r *http.Request) {
+	m, deviates := NewProcessor(d.Logger(), d.Context()).Get()
+	rm := Transform(tenant.MustFromContext(d.Context()).Id().String(), time.Now())(m, deviates)
+
+	query := r.URL.Query()
+	queryParams := jsonapi.ParseQueryFields(&query)
+	server.MarshalResponse[RestModel](d.Logger())(w)(c.ServerInformation())(queryParams)(rm)
+}
+
+func statusOf(err error) int {
+	if errors.Is(err, ErrControlDisabled) {
+		return http.StatusForbidden
+	}
+	if errors.Is(err, ErrInvalidRate) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
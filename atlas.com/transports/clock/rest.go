@@ -0,0 +1,38 @@
+package clock
+
+import "time"
+
+// RestModel is the JSON:API resource for the clock of a tenant. On input, a zero rate or now leaves it unchanged.
+type RestModel struct {
+	Id         string    `json:"-"`
+	Now        time.Time `json:"now"`
+	Rate       float64   `json:"rate"`
+	OffsetMs   int64     `json:"offsetMs"`
+	Overridden bool      `json:"overridden"`
+}
+
+func (r RestModel) GetName() string {
+	return "clocks"
+}
+
+func (r RestModel) GetID() string {
+	return r.Id
+}
+
+func (r *RestModel) SetID(idStr string) error {
+	r.Id = idStr
+	return nil
+}
+
+// Transform converts the clock of a tenant, read at the wall clock instant now, to a RestModel.
+func Transform(tenantId string, now time.Time) func(m Model, deviates bool) RestModel {
+	return func(m Model, deviates bool) RestModel {
+		return RestModel{
+			Id:         tenantId,
+			Now:        m.At(now),
+			Rate:       m.Rate(),
+			OffsetMs:   m.Offset(now).Milliseconds(),
+			Overridden: deviates,
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"atlas-transports/transport"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+
+	KindConfiguration = "configuration"
+	KindOverlap       = "overlap"
+	KindTurnaround    = "turnaround"
+)
+
+// Finding is a problem with the route definitions which would misbehave once deployed.
+type Finding struct {
+	Severity string `json:"severity"`
+	Kind     string `json:"kind"`
+	Subject  string `json:"subject"`
+	Message  string `json:"message"`
+}
+
+// validate checks the definitions as the service does when loading them. Definitions the service would quarantine are
+// left out of the results.
+func validate(routes []transport.Model, vessels []transport.SharedVesselModel) ([]transport.Model, []transport.SharedVesselModel, []Finding) {
+	var findings []Finding
+	routes, vessels, quarantined := transport.ValidateConfiguration(routes, vessels)
+	for _, q := range quarantined {
+		for _, fe := range q.Errors() {
+			findings = append(findings, Finding{SeverityError, KindConfiguration, q.Name(), fmt.Sprintf("%s %s, so it would be quarantined", fe.Field(), fe.Message())})
+		}
+	}
+	return routes, vessels, findings
+}
+
+// checkSchedule checks the trips laid out for the day. As the schedule repeats daily, the last trip of the day is also
+// checked against the first trip of the next.
+func checkSchedule(scheduled []transport.Model, vessels []transport.SharedVesselModel) []Finding {
+	var findings []Finding
+	byId := make(map[uuid.UUID]transport.Model)
+	for _, r := range scheduled {
+		byId[r.Id()] = r
+		trips := sortedTrips(r.Schedule())
+		if len(trips) == 0 {
+			findings = append(findings, Finding{SeverityWarning, KindConfiguration, r.Name(), "route has no trips which complete within the day"})
+			continue
+		}
+		for i := range trips {
+			next, nextDay := following(trips, i)
+			if trips[i].Arrival().After(next.BoardingOpen().Add(nextDay)) {
+				findings = append(findings, Finding{SeverityError, KindOverlap, r.Name(), fmt.Sprintf("trip boarding at [%s] arrives at [%s], after the next trip opens boarding at [%s]", clockTime(trips[i].BoardingOpen()), clockTime(trips[i].Arrival()), clockTime(next.BoardingOpen()))})
+			}
+		}
+	}
+
+	for _, v := range vessels {
+		a, aok := byId[v.RouteAID()]
+		b, bok := byId[v.RouteBID()]
+		if !aok || !bok {
+			continue
+		}
+		trips := sortedTrips(append(append([]transport.TripScheduleModel{}, a.Schedule()...), b.Schedule()...))
+		if len(trips) < 2 {
+			continue
+		}
+		for i := range trips {
+			next, nextDay := following(trips, i)
+			gap := next.BoardingOpen().Add(nextDay).Sub(trips[i].Arrival())
+			if gap < v.TurnaroundDelay() {
+				findings = append(findings, Finding{SeverityError, KindTurnaround, v.Name(), fmt.Sprintf("trip arriving at [%s] leaves [%s] to turn around before the trip boarding at [%s], which needs [%s]", clockTime(trips[i].Arrival()), gap, clockTime(next.BoardingOpen()), v.TurnaroundDelay())})
+			}
+			if next.RouteId() == trips[i].RouteId() {
+				findings = append(findings, Finding{SeverityError, KindTurnaround, v.Name(), fmt.Sprintf("trip boarding at [%s] departs from where the vessel is not, as the trip arriving at [%s] was on the same route", clockTime(next.BoardingOpen()), clockTime(trips[i].Arrival()))})
+			}
+		}
+	}
+	return collapse(findings)
+}
+
+// collapse keeps the first finding of each kind for a subject, noting how many more of it recur through the day.
+func collapse(findings []Finding) []Finding {
+	type key struct{ kind, subject string }
+	counts := make(map[key]int)
+	results := make([]Finding, 0)
+	for _, f := range findings {
+		k := key{f.Kind, f.Subject}
+		if counts[k] == 0 {
+			results = append(results, f)
+		}
+		counts[k]++
+	}
+	for i, f := range results {
+		if n := counts[key{f.Kind, f.Subject}] - 1; n > 0 {
+			results[i].Message = fmt.Sprintf("%s, and [%d] more like it", f.Message, n)
+		}
+	}
+	return results
+}
+
+// following returns the trip after the i-th, and the offset to apply to it when it falls on the next day.
+func following(trips []transport.TripScheduleModel, i int) (transport.TripScheduleModel, time.Duration) {
+	if i+1 < len(trips) {
+		return trips[i+1], 0
+	}
+	return trips[0], 24 * time.Hour
+}
+
+func sortedTrips(trips []transport.TripScheduleModel) []transport.TripScheduleModel {
+	results := append([]transport.TripScheduleModel{}, trips...)
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].BoardingOpen().Before(results[j].BoardingOpen())
+	})
+	return results
+}
+
+func clockTime(t time.Time) string {
+	return t.Format("15:04:05")
+}
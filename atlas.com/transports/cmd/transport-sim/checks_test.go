@@ -0,0 +1,60 @@
+package main
+
+import (
+	"atlas-transports/transport"
+	"testing"
+	"time"
+
+	_map "github.com/Chronicle20/atlas-constants/map"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func testRoute(name string, travel time.Duration, cycle time.Duration) transport.Model {
+	return transport.NewBuilder(name).
+		SetId(uuid.New()).
+		SetStartMapId(100).
+		SetStagingMapId(101).
+		SetEnRouteMapIds([]_map.Id{102}).
+		SetDestinationMapId(103).
+		SetBoardingWindowDuration(4 * time.Minute).
+		SetPreDepartureDuration(1 * time.Minute).
+		SetTravelDuration(travel).
+		SetCycleInterval(cycle).
+		Build()
+}
+
+func TestRun_FlagsOverlappingTrips(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	report := run([]transport.Model{testRoute("Overlapping", 10*time.Minute, 10*time.Minute)}, nil, start, time.Hour)
+
+	// A cycle shorter than a trip is rejected by validation, so the route would be quarantined rather than run
+	assert.Len(t, report.Findings, 1)
+	assert.Equal(t, KindConfiguration, report.Findings[0].Kind)
+	assert.Contains(t, report.Findings[0].Message, "cycleInterval")
+	assert.Empty(t, report.Timeline)
+}
+
+func TestRun_FlagsImpossibleTurnaround(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	a := testRoute("Outbound", 10*time.Minute, 0)
+	b := testRoute("Inbound", 10*time.Minute, 0)
+	// Each leg takes 15 minutes, so with a 10 minute turnaround the day ends on an outbound leg arriving at 23:35, and
+	// the next day starts on the outbound leg again.
+	v := transport.NewSharedVesselBuilder().SetId(uuid.New()).SetName("Ship").SetRouteAID(a.Id()).SetRouteBID(b.Id()).SetTurnaroundDelay(10 * time.Minute).Build()
+
+	report := run([]transport.Model{a, b}, []transport.SharedVesselModel{v}, start, time.Hour)
+	assert.Len(t, report.Findings, 1)
+	assert.Equal(t, KindTurnaround, report.Findings[0].Kind)
+	assert.Equal(t, "Ship", report.Findings[0].Subject)
+}
+
+func TestRun_CleanSchedule(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	report := run([]transport.Model{testRoute("Clean", 10*time.Minute, 20*time.Minute)}, nil, start, time.Hour)
+
+	assert.Empty(t, report.Findings)
+	// Boarding is open at the start, so the first trip has three transitions left and the two after it four each.
+	assert.Len(t, report.Timeline, 1+3+2*4)
+	assert.Equal(t, transport.OpenEntry, report.Timeline[0].To)
+}
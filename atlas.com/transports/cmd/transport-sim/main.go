@@ -0,0 +1,176 @@
+// Command transport-sim checks route definitions before they are deployed. It reads routes and vessels in the shape
+// served by the configuration service, runs the service's scheduler and route state machine against a virtual clock,
+// and prints the resulting timeline of transitions and vessel positions along with any problems found.
+//
+// Usage:
+//
+//	transport-sim -routes routes.json [-vessels vessels.json] [-start 2025-01-01T00:00:00Z] [-duration 24h] [-format text|json]
+//
+// The command exits with status 1 when any error is found.
+package main
+
+import (
+	"atlas-transports/transport"
+	"atlas-transports/transport/config"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jtumidanski/api2go/jsonapi"
+)
+
+// Report is the outcome of a simulation.
+type Report struct {
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Findings []Finding `json:"findings"`
+	Timeline []Event   `json:"timeline"`
+}
+
+func main() {
+	routesPath := flag.String("routes", "", "JSON:API document of routes, as served by the configuration service")
+	vesselsPath := flag.String("vessels", "", "JSON:API document of vessels, as served by the configuration service")
+	startValue := flag.String("start", "", "RFC 3339 instant the simulation starts at (default: midnight UTC today)")
+	duration := flag.Duration("duration", 24*time.Hour, "how long to simulate")
+	format := flag.String("format", "text", "output format, text or json")
+	flag.Parse()
+
+	if *routesPath == "" {
+		fmt.Fprintln(os.Stderr, "-routes is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	start := time.Now().UTC().Truncate(24 * time.Hour)
+	if *startValue != "" {
+		var err error
+		start, err = time.Parse(time.RFC3339, *startValue)
+		if err != nil {
+			fail(fmt.Errorf("invalid -start: %w", err))
+		}
+	}
+
+	routes, err := readRoutes(*routesPath)
+	if err != nil {
+		fail(err)
+	}
+	var vessels []transport.SharedVesselModel
+	if *vesselsPath != "" {
+		vessels, err = readVessels(*vesselsPath)
+		if err != nil {
+			fail(err)
+		}
+	}
+
+	report := run(routes, vessels, start, *duration)
+	switch *format {
+	case "json":
+		err = writeJSON(os.Stdout, report)
+	case "text":
+		err = writeText(os.Stdout, report)
+	default:
+		err = fmt.Errorf("unknown -format [%s]", *format)
+	}
+	if err != nil {
+		fail(err)
+	}
+
+	for _, f := range report.Findings {
+		if f.Severity == SeverityError {
+			os.Exit(1)
+		}
+	}
+}
+
+// run checks and simulates the definitions.
+func run(routes []transport.Model, vessels []transport.SharedVesselModel, start time.Time, duration time.Duration) Report {
+	routes, vessels, findings := validate(routes, vessels)
+	findings = append(findings, checkSchedule(transport.ScheduleRoutes(&virtualClock{now: start}, routes, vessels), vessels)...)
+	return Report{
+		Start:    start,
+		End:      start.Add(duration),
+		Findings: append([]Finding{}, findings...),
+		Timeline: simulate(routes, vessels, start, duration),
+	}
+}
+
+func readRoutes(path string) ([]transport.Model, error) {
+	var rms []config.RouteRestModel
+	if err := unmarshal(path, &rms); err != nil {
+		return nil, err
+	}
+	results := make([]transport.Model, 0, len(rms))
+	for _, rm := range rms {
+		m, err := config.ExtractRoute(rm)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, m)
+	}
+	return results, nil
+}
+
+func readVessels(path string) ([]transport.SharedVesselModel, error) {
+	var rms []config.VesselRestModel
+	if err := unmarshal(path, &rms); err != nil {
+		return nil, err
+	}
+	results := make([]transport.SharedVesselModel, 0, len(rms))
+	for _, rm := range rms {
+		m, err := config.ExtractVessel(rm)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, m)
+	}
+	return results, nil
+}
+
+func unmarshal(path string, target interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err = jsonapi.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("unable to read [%s]: %w", path, err)
+	}
+	return nil
+}
+
+func writeJSON(w io.Writer, report Report) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(report)
+}
+
+func writeText(w io.Writer, report Report) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "Simulated %s to %s.\n\n", report.Start.Format(time.RFC3339), report.End.Format(time.RFC3339))
+	if len(report.Findings) == 0 {
+		fmt.Fprintln(tw, "No problems found.")
+	} else {
+		fmt.Fprintln(tw, "FINDINGS")
+		for _, f := range report.Findings {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", f.Severity, f.Kind, f.Subject, f.Message)
+		}
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "TIMELINE")
+	for _, e := range report.Timeline {
+		from := "-"
+		if e.From != "" {
+			from = string(e.From)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s -> %s\t%s\t%s\n", e.At.Format(time.RFC3339), e.Route, from, e.To, e.Vessel, e.Position)
+	}
+	return tw.Flush()
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(2)
+}
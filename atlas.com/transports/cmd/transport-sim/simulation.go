@@ -0,0 +1,127 @@
+package main
+
+import (
+	"atlas-transports/transport"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// sampleDelay is how long after a scheduled instant route states are sampled, as the update loop would observe them on
+// its next tick rather than on the boundary itself.
+const sampleDelay = time.Millisecond
+
+// virtualClock is a clock which only moves when the simulation advances it.
+type virtualClock struct {
+	now time.Time
+}
+
+func (c *virtualClock) Now() time.Time {
+	return c.now
+}
+
+func (c *virtualClock) After(d time.Duration) <-chan time.Time {
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+// Event is a route entering a state. Events at the start of the simulation have no from state.
+type Event struct {
+	At       time.Time            `json:"at"`
+	RouteId  uuid.UUID            `json:"routeId"`
+	Route    string               `json:"route"`
+	From     transport.RouteState `json:"from,omitempty"`
+	To       transport.RouteState `json:"to"`
+	Vessel   string               `json:"vessel"`
+	Position string               `json:"position"`
+}
+
+// simulate schedules the routes against a virtual clock starting at start, and runs the state machine of every route
+// until the duration has elapsed.
+func simulate(routes []transport.Model, vessels []transport.SharedVesselModel, start time.Time, duration time.Duration) []Event {
+	c := &virtualClock{now: start}
+	scheduled := transport.ScheduleRoutes(c, routes, vessels)
+	vesselNames := vesselNamesByRoute(scheduled, vessels)
+
+	events := make([]Event, 0)
+	current := make([]transport.Model, len(scheduled))
+	for i, route := range scheduled {
+		current[i], _ = route.UpdateState(start)
+		events = append(events, newEvent(start, current[i], "", vesselNames[route.Id()]))
+	}
+
+	end := start.Add(duration)
+	for {
+		next, ok := nextTransitionAt(current, c.Now())
+		if !ok || !next.Before(end) {
+			break
+		}
+		<-c.After(next.Sub(c.Now()))
+		for i, route := range current {
+			r, changed := route.UpdateState(next.Add(sampleDelay))
+			if !changed {
+				continue
+			}
+			events = append(events, newEvent(next, r, route.State(), vesselNames[route.Id()]))
+			current[i] = r
+		}
+	}
+	return events
+}
+
+func nextTransitionAt(routes []transport.Model, now time.Time) (time.Time, bool) {
+	var next time.Time
+	found := false
+	for _, route := range routes {
+		if t, ok := route.NextTransitionAt(now); ok && (!found || t.Before(next)) {
+			next = t
+			found = true
+		}
+	}
+	return next, found
+}
+
+// vesselNamesByRoute names the vessel serving each route. Routes not served by a shared vessel have a vessel of their
+// own, named after the route.
+func vesselNamesByRoute(routes []transport.Model, vessels []transport.SharedVesselModel) map[uuid.UUID]string {
+	results := make(map[uuid.UUID]string)
+	for _, route := range routes {
+		results[route.Id()] = route.Name()
+	}
+	for _, v := range vessels {
+		results[v.RouteAID()] = v.Name()
+		results[v.RouteBID()] = v.Name()
+	}
+	return results
+}
+
+func newEvent(at time.Time, r transport.Model, from transport.RouteState, vessel string) Event {
+	return Event{
+		At:       at,
+		RouteId:  r.Id(),
+		Route:    r.Name(),
+		From:     from,
+		To:       r.State(),
+		Vessel:   vessel,
+		Position: position(r),
+	}
+}
+
+// position describes where the vessel of a route is while the route is in its current state.
+func position(r transport.Model) string {
+	switch r.State() {
+	case transport.OpenEntry:
+		return fmt.Sprintf("boarding at map [%d]", r.StagingMapId())
+	case transport.LockedEntry:
+		return fmt.Sprintf("departing from map [%d]", r.StagingMapId())
+	case transport.InTransit:
+		return fmt.Sprintf("en route to map [%d]", r.DestinationMapId())
+	case transport.AwaitingReturn:
+		return fmt.Sprintf("away from map [%d]", r.StagingMapId())
+	default:
+		return "out of service"
+	}
+}
@@ -0,0 +1,124 @@
+package portal
+
+import (
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	_map "github.com/Chronicle20/atlas-constants/map"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const defaultCacheTTL = 1 * time.Hour
+
+type cacheEntry struct {
+	portals   []Model
+	expiresAt time.Time
+}
+
+// cacheLoad is a refresh of the portals of a map from the DATA service, shared by every caller asking while it runs.
+type cacheLoad struct {
+	done    chan struct{}
+	portals []Model
+	err     error
+}
+
+type Cache struct {
+	mu      sync.RWMutex
+	store   map[uuid.UUID]map[_map.Id]cacheEntry
+	ttl     time.Duration
+	loadsMu sync.Mutex
+	loads   map[uuid.UUID]map[_map.Id]*cacheLoad
+}
+
+var cache *Cache
+var cacheOnce sync.Once
+
+func getCache() *Cache {
+	cacheOnce.Do(func() {
+		cache = newCache(cacheTTL())
+	})
+	return cache
+}
+
+func newCache(ttl time.Duration) *Cache {
+	return &Cache{
+		store: make(map[uuid.UUID]map[_map.Id]cacheEntry),
+		ttl:   ttl,
+		loads: make(map[uuid.UUID]map[_map.Id]*cacheLoad),
+	}
+}
+
+// Get returns the cached portals of the map, and whether they are still fresh. Expired entries are still returned so
+// that they can serve as a fallback when the DATA service is unavailable.
+func (c *Cache) Get(tenantId uuid.UUID, mapId _map.Id, now time.Time) ([]Model, bool, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if maps, ok := c.store[tenantId]; ok {
+		if e, ok := maps[mapId]; ok {
+			return e.portals, true, now.Before(e.expiresAt)
+		}
+	}
+	return nil, false, false
+}
+
+func (c *Cache) Put(tenantId uuid.UUID, mapId _map.Id, portals []Model, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.store[tenantId]; !ok {
+		c.store[tenantId] = make(map[_map.Id]cacheEntry)
+	}
+	c.store[tenantId][mapId] = cacheEntry{portals: portals, expiresAt: now.Add(c.ttl)}
+}
+
+// Load runs the loader for the map, unless a load of the same map is already running, in which case its result is
+// shared instead. This keeps an expired entry from sending a burst of identical requests to the DATA service.
+func (c *Cache) Load(tenantId uuid.UUID, mapId _map.Id, loader func() ([]Model, error)) ([]Model, error) {
+	c.loadsMu.Lock()
+	if l, ok := c.loads[tenantId][mapId]; ok {
+		c.loadsMu.Unlock()
+		<-l.done
+		return l.portals, l.err
+	}
+	if _, ok := c.loads[tenantId]; !ok {
+		c.loads[tenantId] = make(map[_map.Id]*cacheLoad)
+	}
+	l := &cacheLoad{done: make(chan struct{})}
+	c.loads[tenantId][mapId] = l
+	c.loadsMu.Unlock()
+
+	l.portals, l.err = loader()
+
+	c.loadsMu.Lock()
+	delete(c.loads[tenantId], mapId)
+	c.loadsMu.Unlock()
+	close(l.done)
+	return l.portals, l.err
+}
+
+func cacheTTL() time.Duration {
+	if val, ok := os.LookupEnv("PORTAL_CACHE_TTL"); ok {
+		if d, err := time.ParseDuration(val); err == nil && d > 0 {
+			return d
+		}
+	}
+	return defaultCacheTTL
+}
+
+// defaultPortalId is the portal used when the spawn points of a map cannot be resolved. Portal 0 is used when
+// DEFAULT_PORTAL_ID is not set to a valid portal id, which is reported as it is rarely intended.
+func defaultPortalId(l logrus.FieldLogger) uint32 {
+	val, ok := os.LookupEnv("DEFAULT_PORTAL_ID")
+	if !ok {
+		l.Warnf("DEFAULT_PORTAL_ID is not set, using portal [0].")
+		return 0
+	}
+	id, err := strconv.ParseUint(val, 10, 32)
+	if err != nil {
+		l.WithError(err).Errorf("Invalid DEFAULT_PORTAL_ID [%s], using portal [0].", val)
+		return 0
+	}
+	return uint32(id)
+}
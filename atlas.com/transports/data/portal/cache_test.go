@@ -0,0 +1,95 @@
+package portal
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_GetServesExpiredEntries(t *testing.T) {
+	c := newCache(time.Minute)
+	tenantId := uuid.New()
+	now := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	_, found, _ := c.Get(tenantId, 101000000, now)
+	assert.False(t, found)
+
+	c.Put(tenantId, 101000000, []Model{{id: 1}}, now)
+	ps, found, fresh := c.Get(tenantId, 101000000, now.Add(30*time.Second))
+	assert.True(t, found)
+	assert.True(t, fresh)
+	assert.Len(t, ps, 1)
+
+	ps, found, fresh = c.Get(tenantId, 101000000, now.Add(2*time.Minute))
+	assert.True(t, found)
+	assert.False(t, fresh)
+	assert.Len(t, ps, 1)
+
+	_, found, _ = c.Get(uuid.New(), 101000000, now)
+	assert.False(t, found)
+}
+
+func TestCache_LoadSharesConcurrentLoads(t *testing.T) {
+	c := newCache(time.Minute)
+	tenantId := uuid.New()
+
+	var calls atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	loader := func() ([]Model, error) {
+		if calls.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		return []Model{{id: 1}, {id: 2}}, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([][]Model, 5)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		results[0], _ = c.Load(tenantId, 101000000, loader)
+	}()
+	<-started
+	for i := 1; i < len(results); i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = c.Load(tenantId, 101000000, loader)
+		}(i)
+	}
+	// Give the waiting loads time to join the one in flight before it completes
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	for _, r := range results {
+		assert.Len(t, r, 2)
+	}
+
+	// Once complete, the next load runs again
+	_, err := c.Load(tenantId, 101000000, func() ([]Model, error) {
+		return nil, errors.New("unavailable")
+	})
+	assert.Error(t, err)
+}
+
+func TestDefaultPortalId(t *testing.T) {
+	l, hook := test.NewNullLogger()
+
+	t.Setenv("DEFAULT_PORTAL_ID", "3")
+	assert.Equal(t, uint32(3), defaultPortalId(l))
+	assert.Empty(t, hook.Entries)
+
+	t.Setenv("DEFAULT_PORTAL_ID", "sp")
+	assert.Equal(t, uint32(0), defaultPortalId(l))
+	assert.Len(t, hook.Entries, 1)
+}
@@ -1,6 +1,9 @@
 package portal
 
-import _map "github.com/Chronicle20/atlas-constants/map"
+import (
+	_map "github.com/Chronicle20/atlas-constants/map"
+	"github.com/Chronicle20/atlas-model/model"
+)
 
 type Model struct {
 	id          uint32
@@ -17,6 +20,18 @@ func (p Model) Id() uint32 {
 	return p.id
 }
 
+func (p Model) Name() string {
+	return p.name
+}
+
+func (p Model) X() int16 {
+	return p.x
+}
+
+func (p Model) Y() int16 {
+	return p.y
+}
+
 func (p Model) TargetMapId() _map.Id {
 	return p.targetMapId
 }
@@ -32,3 +47,9 @@ func SpawnPoint(m Model) bool {
 func NoTarget(m Model) bool {
 	return m.TargetMapId() == 999999999
 }
+
+func NameFilter(name string) model.Filter[Model] {
+	return func(m Model) bool {
+		return m.Name() == name
+	}
+}
@@ -1,34 +1,78 @@
 package portal
 
 import (
+	"atlas-transports/metrics"
+	"atlas-transports/tracing"
 	"context"
+	"fmt"
 	_map "github.com/Chronicle20/atlas-constants/map"
 	"github.com/Chronicle20/atlas-model/model"
 	"github.com/Chronicle20/atlas-rest/requests"
+	tenant "github.com/Chronicle20/atlas-tenant"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"time"
 )
 
 type Processor interface {
 	InMapProvider(mapId _map.Id) model.Provider[[]Model]
 	RandomSpawnPointProvider(mapId _map.Id) model.Provider[Model]
 	RandomSpawnPointIdProvider(mapId _map.Id) model.Provider[uint32]
+	ByNameIdProvider(mapId _map.Id, name string) model.Provider[uint32]
+	ClosestSpawnPointIdProvider(mapId _map.Id, x int16, y int16) model.Provider[uint32]
+	Preload(mapIds ..._map.Id) error
 }
 
 type ProcessorImpl struct {
 	l   logrus.FieldLogger
 	ctx context.Context
+	t   tenant.Model
 }
 
 func NewProcessor(l logrus.FieldLogger, ctx context.Context) Processor {
 	p := &ProcessorImpl{
 		l:   l,
 		ctx: ctx,
+		t:   tenant.MustFromContext(ctx),
 	}
 	return p
 }
 
+// InMapProvider returns the portals of the map, served from the tenant's portal cache while fresh. Concurrent refreshes
+// of the same map share a single request. When the DATA service cannot be reached, expired cache entries are served
+// instead.
 func (p *ProcessorImpl) InMapProvider(mapId _map.Id) model.Provider[[]Model] {
-	return requests.SliceProvider[RestModel, Model](p.l, p.ctx)(requestAll(mapId), Extract, model.Filters[Model]())
+	return func() ([]Model, error) {
+		now := time.Now()
+		cached, found, fresh := getCache().Get(p.t.Id(), mapId, now)
+		if fresh {
+			return cached, nil
+		}
+		return getCache().Load(p.t.Id(), mapId, func() ([]Model, error) {
+			ps, err := p.requestInMap(mapId)()
+			if err != nil {
+				if found {
+					p.l.WithError(err).Warnf("Unable to refresh portals of map [%d], serving expired cache entry.", mapId)
+					return cached, nil
+				}
+				return nil, err
+			}
+			getCache().Put(p.t.Id(), mapId, ps, now)
+			return ps, nil
+		})
+	}
+}
+
+func (p *ProcessorImpl) requestInMap(mapId _map.Id) model.Provider[[]Model] {
+	return func() ([]Model, error) {
+		start := time.Now()
+		sl, sctx, span := tracing.StartSpan(p.l, p.ctx, "data.portals_in_map", trace.WithAttributes(attribute.Int64("map.id", int64(mapId))))
+		ps, err := requests.SliceProvider[RestModel, Model](sl, sctx)(requestAll(mapId), Extract, model.Filters[Model]())()
+		tracing.EndSpan(span, err)
+		metrics.Request("data", time.Since(start), err)
+		return ps, err
+	}
 }
 
 func (p *ProcessorImpl) RandomSpawnPointProvider(mapId _map.Id) model.Provider[Model] {
@@ -41,8 +85,78 @@ func (p *ProcessorImpl) RandomSpawnPointProvider(mapId _map.Id) model.Provider[M
 	}
 }
 
+// RandomSpawnPointIdProvider returns the id of a random spawn point of the map, falling back to the configured default
+// portal when the spawn points cannot be resolved.
 func (p *ProcessorImpl) RandomSpawnPointIdProvider(mapId _map.Id) model.Provider[uint32] {
-	return model.Map(getId)(p.RandomSpawnPointProvider(mapId))
+	return func() (uint32, error) {
+		id, err := model.Map(getId)(p.RandomSpawnPointProvider(mapId))()
+		if err != nil {
+			dp := defaultPortalId(p.l)
+			p.l.WithError(err).Warnf("Unable to resolve a spawn point in map [%d], using default portal [%d].", mapId, dp)
+			return dp, nil
+		}
+		return id, nil
+	}
+}
+
+// ByNameIdProvider returns the id of the portal of the map with the given name.
+func (p *ProcessorImpl) ByNameIdProvider(mapId _map.Id, name string) model.Provider[uint32] {
+	return func() (uint32, error) {
+		ps, err := model.FilteredProvider(p.InMapProvider(mapId), model.Filters(NameFilter(name)))()
+		if err != nil {
+			return 0, err
+		}
+		if len(ps) == 0 {
+			return 0, fmt.Errorf("no portal named [%s] in map [%d]", name, mapId)
+		}
+		return ps[0].Id(), nil
+	}
+}
+
+// ClosestSpawnPointIdProvider returns the id of the spawn point of the map closest to the given position.
+func (p *ProcessorImpl) ClosestSpawnPointIdProvider(mapId _map.Id, x int16, y int16) model.Provider[uint32] {
+	return func() (uint32, error) {
+		sps, err := model.FilteredProvider(p.InMapProvider(mapId), model.Filters(SpawnPoint, NoTarget))()
+		if err != nil {
+			return 0, err
+		}
+		if len(sps) == 0 {
+			return 0, fmt.Errorf("no spawn point in map [%d]", mapId)
+		}
+		closest := sps[0]
+		for _, sp := range sps[1:] {
+			if distance(sp, x, y) < distance(closest, x, y) {
+				closest = sp
+			}
+		}
+		return closest.Id(), nil
+	}
+}
+
+func distance(m Model, x int16, y int16) int64 {
+	dx := int64(m.X()) - int64(x)
+	dy := int64(m.Y()) - int64(y)
+	return dx*dx + dy*dy
+}
+
+// Preload populates the tenant's portal cache for the given maps.
+func (p *ProcessorImpl) Preload(mapIds ..._map.Id) error {
+	distinct := make(map[_map.Id]bool)
+	for _, mapId := range mapIds {
+		distinct[mapId] = true
+	}
+	var ids []_map.Id
+	for mapId := range distinct {
+		ids = append(ids, mapId)
+	}
+	p.l.Debugf("Preloading portals of [%d] maps for tenant [%s].", len(ids), p.t.Id())
+	return model.ForEachSlice(model.FixedProvider(ids), func(mapId _map.Id) error {
+		_, err := p.InMapProvider(mapId)()
+		if err != nil {
+			p.l.WithError(err).Warnf("Unable to preload portals of map [%d].", mapId)
+		}
+		return nil
+	}, model.ParallelExecute())
 }
 
 func getId(m Model) (uint32, error) {
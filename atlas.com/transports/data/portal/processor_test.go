@@ -0,0 +1,61 @@
+package portal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func cachedProcessor(t *testing.T, ps []Model) Processor {
+	l, _ := test.NewNullLogger()
+	ten, err := tenant.Register(uuid.New(), "GMS", 83, 1)
+	assert.NoError(t, err)
+	getCache().Put(ten.Id(), 101000300, ps, time.Now())
+	return NewProcessor(l, tenant.WithContext(context.Background(), ten))
+}
+
+func TestProcessor_ByNameIdProvider(t *testing.T) {
+	p := cachedProcessor(t, []Model{
+		{id: 0, name: "sp", targetMapId: 999999999},
+		{id: 4, name: "out00", portalType: 2, targetMapId: 101000000},
+		{id: 7, name: "in00", portalType: 2, targetMapId: 101000301},
+	})
+
+	id, err := p.ByNameIdProvider(101000300, "in00")()
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(7), id)
+
+	_, err = p.ByNameIdProvider(101000300, "missing")()
+	assert.Error(t, err)
+}
+
+func TestProcessor_ClosestSpawnPointIdProvider(t *testing.T) {
+	p := cachedProcessor(t, []Model{
+		{id: 0, name: "sp", x: -500, y: 0, targetMapId: 999999999},
+		{id: 1, name: "sp", x: 100, y: 50, targetMapId: 999999999},
+		{id: 2, name: "sp", x: 900, y: 0, targetMapId: 999999999},
+		{id: 3, name: "out00", portalType: 2, x: 120, y: 50, targetMapId: 101000000},
+	})
+
+	id, err := p.ClosestSpawnPointIdProvider(101000300, 150, 40)()
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(1), id)
+
+	id, err = p.ClosestSpawnPointIdProvider(101000300, 800, 300)()
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(2), id)
+}
+
+func TestProcessor_ClosestSpawnPointIdProviderWithoutSpawnPoints(t *testing.T) {
+	p := cachedProcessor(t, []Model{
+		{id: 3, name: "out00", portalType: 2, targetMapId: 101000000},
+	})
+
+	_, err := p.ClosestSpawnPointIdProvider(101000300, 0, 0)()
+	assert.Error(t, err)
+}
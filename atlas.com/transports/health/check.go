@@ -0,0 +1,199 @@
+package health
+
+import (
+	"atlas-transports/channel"
+	consumer "atlas-transports/kafka/consumer"
+	producer2 "atlas-transports/kafka/producer"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultHeartbeatTimeout = 10 * time.Second
+	groupDescribeTimeout    = 2 * time.Second
+	groupStateStable        = "Stable"
+	minPublishFailures      = 3
+
+	groupStatePreparingRebalance  = "PreparingRebalance"
+	groupStateCompletingRebalance = "CompletingRebalance"
+	// groupRebalanceGracePeriod is how long after the consumer group was last seen stable a rebalance is tolerated.
+	groupRebalanceGracePeriod = time.Minute
+)
+
+// Readiness evaluates every readiness check. The service is ready only when all of them are up, and at least one
+// tenant has routes scheduled. A tenant without registered channels or scheduled routes is degraded, which is reported
+// without making the service unready.
+func Readiness(l logrus.FieldLogger, ctx context.Context) ReadinessModel {
+	now := time.Now()
+	rm := ReadinessModel{
+		Heartbeat: heartbeat(l),
+		Producer:  producer(now),
+		Consumer:  consumerGroup(ctx, getRegistry().watchedConsumerGroup(), now),
+		Tenants:   make(map[string]TenantModel),
+	}
+	ready := rm.Heartbeat.Status == StatusUp && rm.Producer.Status == StatusUp && rm.Consumer.Status == StatusUp
+	healthy := true
+
+	scheduled := false
+	for _, cs := range getRegistry().configurationStatuses() {
+		if cs.err == "" && cs.routes > 0 {
+			scheduled = true
+		}
+		tm := tenantReadiness(l, ctx, cs)
+		switch tm.Status {
+		case StatusDown:
+			ready = false
+		case StatusDegraded:
+			healthy = false
+		}
+		rm.Tenants[cs.tenant.Id().String()] = tm
+	}
+	if !scheduled {
+		ready = false
+	}
+
+	switch {
+	case !ready:
+		rm.Status = StatusDown
+	case !healthy:
+		rm.Status = StatusDegraded
+	default:
+		rm.Status = StatusUp
+	}
+	return rm
+}
+
+// tenantReadiness is down when the route configuration of the tenant failed to load, and degraded when no routes are
+// scheduled or no channels are registered for it, as only that tenant's transports are affected.
+func tenantReadiness(l logrus.FieldLogger, ctx context.Context, cs configurationStatus) TenantModel {
+	tm := TenantModel{Status: StatusUp, Routes: cs.routes, Quarantined: cs.quarantined, Configuration: up("")}
+	channels := channel.NewProcessor(l, tenant.WithContext(ctx, cs.tenant)).GetAll()
+	if len(channels) == 0 {
+		tm.Channels = degraded("no channels registered")
+		tm.Status = StatusDegraded
+	} else {
+		tm.Channels = up(fmt.Sprintf("%d channels registered", len(channels)))
+	}
+	if cs.routes == 0 {
+		tm.Configuration = degraded("no routes scheduled")
+		tm.Status = StatusDegraded
+	}
+	if cs.err != "" {
+		tm.Configuration = down(cs.err)
+		tm.Status = StatusDown
+	}
+	return tm
+}
+
+func heartbeat(l logrus.FieldLogger) CheckModel {
+	last := getRegistry().lastHeartbeat()
+	if last.IsZero() {
+		return down("update loop has not run")
+	}
+	age := time.Since(last)
+	if age > heartbeatTimeout(l) {
+		return down(fmt.Sprintf("update loop last ran %s ago", age.Round(time.Second)))
+	}
+	return up("")
+}
+
+func heartbeatTimeout(l logrus.FieldLogger) time.Duration {
+	if val, ok := os.LookupEnv("HEARTBEAT_TIMEOUT"); ok {
+		if d, err := time.ParseDuration(val); err == nil && d > 0 {
+			return d
+		}
+		l.Warnf("Invalid HEARTBEAT_TIMEOUT [%s], using default.", val)
+	}
+	return defaultHeartbeatTimeout
+}
+
+// producer is down when at least minPublishFailures produce calls failed within the publish window, and they make up
+// at least half of the calls made in it. A single failed call among many successes does not fail readiness.
+func producer(now time.Time) CheckModel {
+	return publishCheck(producer2.PublishStatus(now))
+}
+
+func publishCheck(attempts int, failures int, err error) CheckModel {
+	if failures >= minPublishFailures && failures*2 >= attempts {
+		return down(fmt.Sprintf("%d of %d produce calls failed in the last %s: %s", failures, attempts, producer2.PublishWindow, err))
+	}
+	return up("")
+}
+
+// groupDescriber reports the state of a consumer group, and how many members it has.
+type groupDescriber func(ctx context.Context, brokers []string, groupId string) (string, int, error)
+
+var describeGroup groupDescriber = describeKafkaGroup
+
+// consumerGroup is up when the service's consumer group is stable and has members, which requires the consumers to
+// have joined the group and to be heartbeating to its coordinator. A rebalance is tolerated for
+// groupRebalanceGracePeriod after the group was last seen stable, so that every replica is not made unready whenever
+// one joins or leaves.
+func consumerGroup(ctx context.Context, groupId string, now time.Time) CheckModel {
+	if groupId == "" {
+		return down("no consumer group registered")
+	}
+	var brokers []string
+	for _, list := range consumer.LookupBrokers() {
+		for _, broker := range strings.Split(list, ",") {
+			if broker = strings.TrimSpace(broker); broker != "" {
+				brokers = append(brokers, broker)
+			}
+		}
+	}
+	if len(brokers) == 0 {
+		return down("no bootstrap servers configured")
+	}
+
+	dctx, cancel := context.WithTimeout(ctx, groupDescribeTimeout)
+	defer cancel()
+	state, members, err := describeGroup(dctx, brokers, groupId)
+	if err != nil {
+		return down(err.Error())
+	}
+	detail := fmt.Sprintf("group [%s] is %s with %d members", groupId, state, members)
+	switch state {
+	case groupStateStable:
+		if members == 0 {
+			return down(detail)
+		}
+		getRegistry().groupStable(now)
+		return up(detail)
+	case groupStatePreparingRebalance, groupStateCompletingRebalance:
+		if stableAt := getRegistry().lastGroupStable(); !stableAt.IsZero() && now.Sub(stableAt) <= groupRebalanceGracePeriod {
+			return up(detail)
+		}
+	}
+	return down(detail)
+}
+
+func describeKafkaGroup(ctx context.Context, brokers []string, groupId string) (string, int, error) {
+	var errs []string
+	for _, broker := range brokers {
+		c := &kafka.Client{Addr: kafka.TCP(broker), Timeout: groupDescribeTimeout}
+		resp, err := c.DescribeGroups(ctx, &kafka.DescribeGroupsRequest{GroupIDs: []string{groupId}})
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		for _, g := range resp.Groups {
+			if g.GroupID != groupId {
+				continue
+			}
+			if g.Error != nil {
+				return "", 0, g.Error
+			}
+			return g.GroupState, len(g.Members), nil
+		}
+		return "", 0, fmt.Errorf("group [%s] was not described", groupId)
+	}
+	return "", 0, errors.New(strings.Join(errs, "; "))
+}
@@ -0,0 +1,96 @@
+package health
+
+import (
+	"atlas-transports/channel"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func stableGroup(members int) groupDescriber {
+	return func(ctx context.Context, brokers []string, groupId string) (string, int, error) {
+		return groupStateStable, members, nil
+	}
+}
+
+func TestReadiness_TenantWithoutChannelsOrRoutesIsDegraded(t *testing.T) {
+	l, _ := test.NewNullLogger()
+	t.Setenv("BOOTSTRAP_SERVERS", "kafka:9092")
+	describeGroup = stableGroup(2)
+	defer func() { describeGroup = describeKafkaGroup }()
+	WatchConsumerGroup("Transport Service")
+	Beat()
+	getRegistry().configurations = make(map[uuid.UUID]configurationStatus)
+
+	// A replica without any routes scheduled is not ready
+	empty, _ := tenant.Register(uuid.New(), "GMS", 83, 1)
+	_ = channel.NewProcessor(l, tenant.WithContext(context.Background(), empty)).Register(0, 0)
+	ConfigurationLoaded(empty, 0, 1, nil)
+
+	rm := Readiness(l, context.Background())
+	assert.Equal(t, StatusDown, rm.Status)
+	assert.Equal(t, StatusDegraded, rm.Tenants[empty.Id().String()].Status)
+	assert.Equal(t, StatusDegraded, rm.Tenants[empty.Id().String()].Configuration.Status)
+
+	served, _ := tenant.Register(uuid.New(), "GMS", 83, 1)
+	_ = channel.NewProcessor(l, tenant.WithContext(context.Background(), served)).Register(0, 0)
+	ConfigurationLoaded(served, 2, 0, nil)
+	idle, _ := tenant.Register(uuid.New(), "GMS", 83, 1)
+	ConfigurationLoaded(idle, 1, 0, nil)
+
+	rm = Readiness(l, context.Background())
+	assert.Equal(t, StatusDegraded, rm.Status)
+	assert.Equal(t, StatusUp, rm.Tenants[served.Id().String()].Status)
+	assert.Equal(t, StatusDegraded, rm.Tenants[idle.Id().String()].Status)
+	assert.Equal(t, StatusDegraded, rm.Tenants[idle.Id().String()].Channels.Status)
+
+	failed, _ := tenant.Register(uuid.New(), "GMS", 83, 1)
+	_ = channel.NewProcessor(l, tenant.WithContext(context.Background(), failed)).Register(0, 0)
+	ConfigurationLoaded(failed, 0, 0, errors.New("unable to reach configuration service"))
+
+	rm = Readiness(l, context.Background())
+	assert.Equal(t, StatusDown, rm.Status)
+	assert.Equal(t, StatusDown, rm.Tenants[failed.Id().String()].Status)
+}
+
+func TestConsumerGroup_ToleratesRebalancesAfterStable(t *testing.T) {
+	t.Setenv("BOOTSTRAP_SERVERS", "kafka:9092, kafka:9093")
+	defer func() { describeGroup = describeKafkaGroup }()
+	getRegistry().groupStable(time.Time{})
+	now := time.Now()
+
+	var described []string
+	rebalancing := func(ctx context.Context, brokers []string, groupId string) (string, int, error) {
+		described = brokers
+		return groupStatePreparingRebalance, 1, nil
+	}
+	describeGroup = rebalancing
+	assert.Equal(t, StatusDown, consumerGroup(context.Background(), "Transport Service", now).Status)
+	assert.Equal(t, []string{"kafka:9092", "kafka:9093"}, described)
+
+	describeGroup = stableGroup(0)
+	assert.Equal(t, StatusDown, consumerGroup(context.Background(), "Transport Service", now).Status)
+
+	describeGroup = stableGroup(1)
+	assert.Equal(t, StatusUp, consumerGroup(context.Background(), "Transport Service", now).Status)
+	assert.Equal(t, StatusDown, consumerGroup(context.Background(), "", now).Status)
+
+	// Once the group has been stable, a rebalance is tolerated for the grace period
+	describeGroup = rebalancing
+	assert.Equal(t, StatusUp, consumerGroup(context.Background(), "Transport Service", now.Add(groupRebalanceGracePeriod)).Status)
+	assert.Equal(t, StatusDown, consumerGroup(context.Background(), "Transport Service", now.Add(groupRebalanceGracePeriod+time.Second)).Status)
+}
+
+func TestPublishCheck_ToleratesIsolatedFailures(t *testing.T) {
+	err := errors.New("broker unavailable")
+	assert.Equal(t, StatusUp, publishCheck(0, 0, nil).Status)
+	assert.Equal(t, StatusUp, publishCheck(2, 2, err).Status)
+	assert.Equal(t, StatusUp, publishCheck(100, 3, err).Status)
+	assert.Equal(t, StatusDown, publishCheck(6, 3, err).Status)
+}
@@ -0,0 +1,38 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+// InitHandlers registers the liveness and readiness endpoints.
+func InitHandlers(l logrus.FieldLogger) func(mux *http.ServeMux) {
+	return func(mux *http.ServeMux) {
+		mux.HandleFunc("/health/live", LiveHandler)
+		mux.HandleFunc("/health/ready", ReadyHandler(l))
+	}
+}
+
+// LiveHandler reports that the process is serving requests.
+func LiveHandler(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+// ReadyHandler reports whether the service is able to do its work. The checks are returned in the body, and any
+// failing check makes the response 503. A degraded tenant is reported, but does not fail readiness.
+func ReadyHandler(l logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rm := Readiness(l, r.Context())
+		w.Header().Set("Content-Type", "application/json")
+		if rm.Status == StatusDown {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+		if err := json.NewEncoder(w).Encode(rm); err != nil {
+			l.WithError(err).Errorf("Unable to write readiness response.")
+		}
+	}
+}
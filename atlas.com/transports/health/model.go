@@ -0,0 +1,43 @@
+package health
+
+const (
+	StatusUp       = "up"
+	StatusDegraded = "degraded"
+	StatusDown     = "down"
+)
+
+// CheckModel is the outcome of a single readiness check.
+type CheckModel struct {
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// TenantModel is the readiness of a single tenant.
+type TenantModel struct {
+	Status        string     `json:"status"`
+	Configuration CheckModel `json:"configuration"`
+	Routes        int        `json:"routes"`
+	Quarantined   int        `json:"quarantined"`
+	Channels      CheckModel `json:"channels"`
+}
+
+// ReadinessModel is the body of the readiness endpoint.
+type ReadinessModel struct {
+	Status    string                 `json:"status"`
+	Heartbeat CheckModel             `json:"heartbeat"`
+	Producer  CheckModel             `json:"producer"`
+	Consumer  CheckModel             `json:"consumer"`
+	Tenants   map[string]TenantModel `json:"tenants"`
+}
+
+func up(detail string) CheckModel {
+	return CheckModel{Status: StatusUp, Detail: detail}
+}
+
+func degraded(detail string) CheckModel {
+	return CheckModel{Status: StatusDegraded, Detail: detail}
+}
+
+func down(detail string) CheckModel {
+	return CheckModel{Status: StatusDown, Detail: detail}
+}
@@ -0,0 +1,103 @@
+package health
+
+import (
+	"sync"
+	"time"
+
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/google/uuid"
+)
+
+// configurationStatus is the outcome of loading the route configuration of a tenant.
+type configurationStatus struct {
+	tenant      tenant.Model
+	routes      int
+	quarantined int
+	err         string
+	loadedAt    time.Time
+}
+
+// Registry holds the signals readiness is judged on, as reported by the rest of the service.
+type Registry struct {
+	mu             sync.RWMutex
+	configurations map[uuid.UUID]configurationStatus
+	heartbeat      time.Time
+	consumerGroup  string
+	groupStableAt  time.Time
+}
+
+var registry *Registry
+var registryOnce sync.Once
+
+func getRegistry() *Registry {
+	registryOnce.Do(func() {
+		registry = &Registry{
+			configurations: make(map[uuid.UUID]configurationStatus),
+		}
+	})
+	return registry
+}
+
+// ConfigurationLoaded records the outcome of loading the route configuration of the tenant. Quarantined definitions are
+// reported, but do not make the tenant unready. A tenant with no routes scheduled is degraded.
+func ConfigurationLoaded(t tenant.Model, routes int, quarantined int, err error) {
+	r := getRegistry()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cs := configurationStatus{tenant: t, routes: routes, quarantined: quarantined, loadedAt: time.Now()}
+	if err != nil {
+		cs.err = err.Error()
+	}
+	r.configurations[t.Id()] = cs
+}
+
+// Beat records that the route update loop completed a pass.
+func Beat() {
+	r := getRegistry()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.heartbeat = time.Now()
+}
+
+// WatchConsumerGroup records the consumer group whose membership readiness is judged on.
+func WatchConsumerGroup(groupId string) {
+	r := getRegistry()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.consumerGroup = groupId
+}
+
+func (r *Registry) configurationStatuses() []configurationStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var results []configurationStatus
+	for _, cs := range r.configurations {
+		results = append(results, cs)
+	}
+	return results
+}
+
+func (r *Registry) lastHeartbeat() time.Time {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.heartbeat
+}
+
+// groupStable records that the consumer group was seen stable with members.
+func (r *Registry) groupStable(at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.groupStableAt = at
+}
+
+func (r *Registry) lastGroupStable() time.Time {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.groupStableAt
+}
+
+func (r *Registry) watchedConsumerGroup() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.consumerGroup
+}
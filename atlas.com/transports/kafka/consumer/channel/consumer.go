@@ -4,6 +4,7 @@ import (
 	"atlas-transports/channel"
 	consumer2 "atlas-transports/kafka/consumer"
 	channel2 "atlas-transports/kafka/message/channel"
+	"atlas-transports/transport"
 	"context"
 	channel3 "github.com/Chronicle20/atlas-constants/channel"
 	"github.com/Chronicle20/atlas-constants/world"
@@ -35,6 +36,9 @@ func handleEventStatus(l logrus.FieldLogger, ctx context.Context, e channel2.Sta
 	if e.Type == channel2.StatusTypeStarted {
 		l.Debugf("Registering channel [%d] for world [%d].", e.ChannelId, e.WorldId)
 		_ = channel.NewProcessor(l, ctx).Register(world.Id(e.WorldId), channel3.Id(e.ChannelId))
+
+		// Let the channel render vessels which are mid-cycle
+		_ = transport.NewProcessor(l, ctx).SendChannelSnapshotsAndEmit(world.Id(e.WorldId), channel3.Id(e.ChannelId))
 	} else if e.Type == channel2.StatusTypeShutdown {
 		l.Debugf("Unregistering channel [%d] for world [%d].", e.ChannelId, e.WorldId)
 		_ = channel.NewProcessor(l, ctx).Unregister(world.Id(e.WorldId), channel3.Id(e.ChannelId))
@@ -3,6 +3,7 @@ package character
 import (
 	consumer2 "atlas-transports/kafka/consumer"
 	character2 "atlas-transports/kafka/message/character"
+	"atlas-transports/occupancy"
 	"atlas-transports/transport"
 	"context"
 	"github.com/Chronicle20/atlas-constants/channel"
@@ -30,6 +31,9 @@ func InitHandlers(l logrus.FieldLogger) func(rf func(topic string, handler handl
 		var t string
 		t, _ = topic.EnvProvider(l)(character2.EnvEventTopicStatus)()
 		_, _ = rf(t, message2.AdaptHandler(message2.PersistentConfig(handleEventStatus)))
+		_, _ = rf(t, message2.AdaptHandler(message2.PersistentConfig(handleLoginEventStatus)))
+		_, _ = rf(t, message2.AdaptHandler(message2.PersistentConfig(handleMapChangedEventStatus)))
+		_, _ = rf(t, message2.AdaptHandler(message2.PersistentConfig(handleObservationMapEntered)))
 	}
 }
 
@@ -40,7 +44,47 @@ func handleEventStatus(l logrus.FieldLogger, ctx context.Context, e character2.S
 
 	l.Debugf("Character [%d] logged out in map [%d].", e.CharacterId, e.Body.MapId)
 
-	// Warp character to route start map if they logged out in a transport map
+	_ = occupancy.NewProcessor(l, ctx).Leave(e.CharacterId)
+
+	// Apply the route's logout policy if they logged out in a transport map
 	f := field.NewBuilder(world.Id(e.WorldId), channel.Id(e.Body.ChannelId), map2.Id(e.Body.MapId)).Build()
-	_ = transport.NewProcessor(l, ctx).WarpToRouteStartMapOnLogoutAndEmit(e.CharacterId, f)
+	_ = transport.NewProcessor(l, ctx).RelocateOnLogoutAndEmit(e.CharacterId, f)
+}
+
+func handleLoginEventStatus(l logrus.FieldLogger, ctx context.Context, e character2.StatusEvent[character2.LoginStatusEventBody]) {
+	if e.Type != character2.StatusEventTypeLogin {
+		return
+	}
+
+	l.Debugf("Character [%d] logged in to map [%d].", e.CharacterId, e.Body.MapId)
+
+	f := field.NewBuilder(world.Id(e.WorldId), channel.Id(e.Body.ChannelId), map2.Id(e.Body.MapId)).Build()
+	_ = occupancy.NewProcessor(l, ctx).Enter(e.CharacterId, f)
+
+	// Apply any relocation deferred at logout, or move them out of a transport map they no longer belong in
+	_ = transport.NewProcessor(l, ctx).RelocateOnLoginAndEmit(e.CharacterId, f)
+}
+
+func handleMapChangedEventStatus(l logrus.FieldLogger, ctx context.Context, e character2.StatusEvent[character2.MapChangedStatusEventBody]) {
+	if e.Type != character2.StatusEventTypeMapChanged {
+		return
+	}
+
+	l.Debugf("Character [%d] changed from map [%d] to map [%d].", e.CharacterId, e.Body.OldMapId, e.Body.TargetMapId)
+
+	f := field.NewBuilder(world.Id(e.WorldId), channel.Id(e.Body.ChannelId), map2.Id(e.Body.TargetMapId)).Build()
+	_ = occupancy.NewProcessor(l, ctx).Enter(e.CharacterId, f)
+
+	// Move them out if they entered a transport map they no longer belong in
+	_ = transport.NewProcessor(l, ctx).ReconcilePassengerAndEmit(e.CharacterId, f)
+}
+
+func handleObservationMapEntered(l logrus.FieldLogger, ctx context.Context, e character2.StatusEvent[character2.MapChangedStatusEventBody]) {
+	if e.Type != character2.StatusEventTypeMapChanged {
+		return
+	}
+
+	// Let the channel render the vessels observed from the map the character entered
+	f := field.NewBuilder(world.Id(e.WorldId), channel.Id(e.Body.ChannelId), map2.Id(e.Body.TargetMapId)).Build()
+	_ = transport.NewProcessor(l, ctx).SendObservationSnapshotsAndEmit(f)
 }
@@ -25,8 +25,10 @@ type ChangeMapBody struct {
 }
 
 const (
-	EnvEventTopicStatus   = "EVENT_TOPIC_CHARACTER_STATUS"
-	StatusEventTypeLogout = "LOGOUT"
+	EnvEventTopicStatus       = "EVENT_TOPIC_CHARACTER_STATUS"
+	StatusEventTypeLogin      = "LOGIN"
+	StatusEventTypeLogout     = "LOGOUT"
+	StatusEventTypeMapChanged = "MAP_CHANGED"
 )
 
 type StatusEvent[E any] struct {
@@ -40,3 +42,15 @@ type LogoutStatusEventBody struct {
 	ChannelId byte   `json:"channelId"`
 	MapId     uint32 `json:"mapId"`
 }
+
+type LoginStatusEventBody struct {
+	ChannelId byte   `json:"channelId"`
+	MapId     uint32 `json:"mapId"`
+}
+
+type MapChangedStatusEventBody struct {
+	ChannelId      byte   `json:"channelId"`
+	OldMapId       uint32 `json:"oldMapId"`
+	TargetMapId    uint32 `json:"targetMapId"`
+	TargetPortalId uint32 `json:"targetPortalId"`
+}
@@ -9,8 +9,9 @@ import (
 )
 
 type Buffer struct {
-	mu     sync.Mutex
-	buffer map[string][]kafka.Message
+	mu      sync.Mutex
+	buffer  map[string][]kafka.Message
+	emitted []func(err error)
 }
 
 func NewBuffer() *Buffer {
@@ -30,6 +31,25 @@ func (b *Buffer) Put(t string, p model.Provider[[]kafka.Message]) error {
 	return nil
 }
 
+// OnEmit registers a function to be called once the buffer has been published, with the error which stopped it, if
+// any. Functions are called in the order they were registered.
+func (b *Buffer) OnEmit(f func(err error)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.emitted = append(b.emitted, f)
+}
+
+func (b *Buffer) emit(err error) error {
+	b.mu.Lock()
+	fs := b.emitted
+	b.emitted = nil
+	b.mu.Unlock()
+	for _, f := range fs {
+		f(err)
+	}
+	return err
+}
+
 func (b *Buffer) GetAll() map[string][]kafka.Message {
 	b.mu.Lock()
 	defer b.mu.Unlock()
@@ -46,15 +66,15 @@ func Emit(p producer.Provider) func(f func(buf *Buffer) error) error {
 		b := NewBuffer()
 		err := f(b)
 		if err != nil {
-			return err
+			return b.emit(err)
 		}
 		for t, ms := range b.GetAll() {
 			err = p(t)(model.FixedProvider(ms))
 			if err != nil {
-				return err
+				return b.emit(err)
 			}
 		}
-		return nil
+		return b.emit(nil)
 	}
 }
 
@@ -64,14 +84,14 @@ func EmitWithResult[M any, B any](p producer.Provider) func(func(*Buffer) func(B
 			var buf = NewBuffer()
 			result, err := f(buf)(input)
 			if err != nil {
-				return result, err
+				return result, buf.emit(err)
 			}
 			for t, ms := range buf.GetAll() {
 				if err = p(t)(model.FixedProvider(ms)); err != nil {
-					return result, err
+					return result, buf.emit(err)
 				}
 			}
-			return result, nil
+			return result, buf.emit(nil)
 		}
 	}
 }
@@ -0,0 +1,42 @@
+package message
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Chronicle20/atlas-kafka/producer"
+	"github.com/Chronicle20/atlas-model/model"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmit_NotifiesOnceBufferIsPublished(t *testing.T) {
+	var published []string
+	fail := false
+	p := func(token string) producer.MessageProducer {
+		return func(provider model.Provider[[]kafka.Message]) error {
+			if fail {
+				return errors.New("broker unavailable")
+			}
+			published = append(published, token)
+			return nil
+		}
+	}
+
+	var outcomes []error
+	buffer := func(mb *Buffer) error {
+		mb.OnEmit(func(err error) {
+			assert.Len(t, published, 1)
+			outcomes = append(outcomes, err)
+		})
+		return mb.Put("COMMAND_TOPIC_CHARACTER", model.FixedProvider([]kafka.Message{{Value: []byte("{}")}}))
+	}
+
+	assert.NoError(t, Emit(p)(buffer))
+	fail = true
+	assert.Error(t, Emit(p)(buffer))
+	if assert.Len(t, outcomes, 2) {
+		assert.NoError(t, outcomes[0])
+		assert.EqualError(t, outcomes[1], "broker unavailable")
+	}
+}
@@ -1,14 +1,18 @@
 package transport
 
 import (
+	"github.com/Chronicle20/atlas-constants/channel"
 	_map "github.com/Chronicle20/atlas-constants/map"
+	"github.com/Chronicle20/atlas-constants/world"
 	"github.com/google/uuid"
+	"time"
 )
 
 const (
 	EnvEventTopicStatus = "EVENT_TOPIC_TRANSPORT_STATUS"
 	EventStatusArrived  = "ARRIVED"
 	EventStatusDeparted = "DEPARTED"
+	EventStatusSnapshot = "SNAPSHOT"
 )
 
 type StatusEvent[E any] struct {
@@ -17,10 +21,60 @@ type StatusEvent[E any] struct {
 	Body    E         `json:"body"`
 }
 
+// ArrivedStatusEventBody announces a vessel arriving. The world and channel are only set for routes which stagger
+// their channels, each of which is sent its own event once its offset has elapsed.
 type ArrivedStatusEventBody struct {
-	MapId _map.Id `json:"mapId"`
+	MapId     _map.Id     `json:"mapId"`
+	WorldId   *world.Id   `json:"worldId,omitempty"`
+	ChannelId *channel.Id `json:"channelId,omitempty"`
 }
 
+// DepartedStatusEventBody announces a vessel departing. The world and channel are only set for routes which stagger
+// their channels, each of which is sent its own event once its offset has elapsed.
 type DepartedStatusEventBody struct {
-	MapId _map.Id `json:"mapId"`
+	MapId     _map.Id     `json:"mapId"`
+	WorldId   *world.Id   `json:"worldId,omitempty"`
+	ChannelId *channel.Id `json:"channelId,omitempty"`
+}
+
+// SnapshotStatusEventBody describes the current state of a route to a single channel, so that a channel which started,
+// or a character who arrived, mid-cycle can render the vessel correctly.
+type SnapshotStatusEventBody struct {
+	WorldId          world.Id   `json:"worldId"`
+	ChannelId        channel.Id `json:"channelId"`
+	StartMapId       _map.Id    `json:"startMapId"`
+	DestinationMapId _map.Id    `json:"destinationMapId"`
+	ObservationMapId _map.Id    `json:"observationMapId"`
+	State            string     `json:"state"`
+	NextTransitionAt time.Time  `json:"nextTransitionAt"`
+	RemainingMillis  int64      `json:"remainingMillis"`
+}
+
+const (
+	EnvEventTopicPassenger      = "EVENT_TOPIC_TRANSPORT_PASSENGER"
+	PassengerEventTypeRecovered = "RECOVERED"
+	PassengerEventTypeLogout    = "LOGOUT_DECISION"
+)
+
+type PassengerEvent[E any] struct {
+	RouteId     uuid.UUID `json:"routeId"`
+	WorldId     world.Id  `json:"worldId"`
+	CharacterId uint32    `json:"characterId"`
+	Type        string    `json:"type"`
+	Body        E         `json:"body"`
+}
+
+type RecoveredPassengerEventBody struct {
+	ChannelId  channel.Id `json:"channelId"`
+	FromMapId  _map.Id    `json:"fromMapId"`
+	ToMapId    _map.Id    `json:"toMapId"`
+	RouteState string     `json:"routeState"`
+}
+
+type LogoutDecisionPassengerEventBody struct {
+	ChannelId   channel.Id `json:"channelId"`
+	MapId       _map.Id    `json:"mapId"`
+	RouteState  string     `json:"routeState"`
+	Policy      string     `json:"policy"`
+	TargetMapId _map.Id    `json:"targetMapId"`
 }
@@ -0,0 +1,151 @@
+package producer
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Chronicle20/atlas-model/model"
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+)
+
+// HeaderIdempotencyKey is the Kafka header carrying a deterministic key downstream consumers may use to dedupe commands.
+const HeaderIdempotencyKey = "IDEMPOTENCY_KEY"
+
+// idempotencyRetention is how long a published key is remembered. Keys embed the trip occurrence, so a day is sufficient.
+const idempotencyRetention = 24 * time.Hour
+
+// IdempotencyRegistry remembers the idempotency keys published for each tenant, and the keys of publishes in flight.
+// It is held in memory only, so every key is forgotten when the service restarts. Downstream consumers must still
+// dedupe on the IDEMPOTENCY_KEY header to guard against a restart replaying a publish.
+type IdempotencyRegistry struct {
+	mu        sync.Mutex
+	published map[uuid.UUID]map[string]time.Time
+	reserved  map[uuid.UUID]map[string]bool
+	lastPurge time.Time
+}
+
+var idempotencyRegistry *IdempotencyRegistry
+var idempotencyRegistryOnce sync.Once
+
+func getIdempotencyRegistry() *IdempotencyRegistry {
+	idempotencyRegistryOnce.Do(func() {
+		idempotencyRegistry = &IdempotencyRegistry{
+			published: make(map[uuid.UUID]map[string]time.Time),
+			reserved:  make(map[uuid.UUID]map[string]bool),
+		}
+	})
+	return idempotencyRegistry
+}
+
+// Published reports whether a message carrying the key was already published for the tenant.
+func (r *IdempotencyRegistry) Published(tenantId uuid.UUID, key string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if keys, ok := r.published[tenantId]; ok {
+		_, ok = keys[key]
+		return ok
+	}
+	return false
+}
+
+// Reserve claims the key for a publish about to be made for the tenant. It fails when the key was already published,
+// or is claimed by a publish still in flight. A reserved key must be recorded or released once the publish completes.
+func (r *IdempotencyRegistry) Reserve(tenantId uuid.UUID, key string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.published[tenantId][key]; ok {
+		return false
+	}
+	if r.reserved[tenantId][key] {
+		return false
+	}
+	if _, ok := r.reserved[tenantId]; !ok {
+		r.reserved[tenantId] = make(map[string]bool)
+	}
+	r.reserved[tenantId][key] = true
+	return true
+}
+
+// Release gives up the reservation of keys whose publish failed, so that they can be published again.
+func (r *IdempotencyRegistry) Release(tenantId uuid.UUID, keys ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.release(tenantId, keys...)
+}
+
+func (r *IdempotencyRegistry) release(tenantId uuid.UUID, keys ...string) {
+	if reserved, ok := r.reserved[tenantId]; ok {
+		for _, key := range keys {
+			delete(reserved, key)
+		}
+		if len(reserved) == 0 {
+			delete(r.reserved, tenantId)
+		}
+	}
+}
+
+// Record marks the keys as published for the tenant, releasing any reservation of them.
+func (r *IdempotencyRegistry) Record(tenantId uuid.UUID, keys ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	r.purge(now)
+	if _, ok := r.published[tenantId]; !ok {
+		r.published[tenantId] = make(map[string]time.Time)
+	}
+	for _, key := range keys {
+		r.published[tenantId][key] = now
+	}
+	r.release(tenantId, keys...)
+}
+
+func (r *IdempotencyRegistry) purge(now time.Time) {
+	if now.Sub(r.lastPurge) < time.Minute {
+		return
+	}
+	r.lastPurge = now
+	for tenantId, keys := range r.published {
+		for key, at := range keys {
+			if now.Sub(at) > idempotencyRetention {
+				delete(keys, key)
+			}
+		}
+		if len(keys) == 0 {
+			delete(r.published, tenantId)
+		}
+	}
+}
+
+// IdempotencyKeyPublished reports whether a message with the given idempotency key was already published for the tenant.
+func IdempotencyKeyPublished(tenantId uuid.UUID, key string) bool {
+	return getIdempotencyRegistry().Published(tenantId, key)
+}
+
+// WithIdempotencyKey decorates every message produced by the provider with the idempotency key header.
+func WithIdempotencyKey(key string) model.Decorator[model.Provider[[]kafka.Message]] {
+	return func(p model.Provider[[]kafka.Message]) model.Provider[[]kafka.Message] {
+		if key == "" {
+			return p
+		}
+		return func() ([]kafka.Message, error) {
+			ms, err := p()
+			if err != nil {
+				return nil, err
+			}
+			for i := range ms {
+				ms[i].Headers = append(ms[i].Headers, kafka.Header{Key: HeaderIdempotencyKey, Value: []byte(key)})
+			}
+			return ms, nil
+		}
+	}
+}
+
+func idempotencyKey(m kafka.Message) (string, bool) {
+	for _, h := range m.Headers {
+		if h.Key == HeaderIdempotencyKey {
+			return string(h.Value), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,49 @@
+package producer
+
+import (
+	"context"
+	"slices"
+
+	"github.com/Chronicle20/atlas-kafka/producer"
+	"github.com/Chronicle20/atlas-model/model"
+	"github.com/segmentio/kafka-go"
+)
+
+// Pacer limits the rate messages are published at.
+type Pacer interface {
+	// Burst returns the most messages which may be published at once
+	Burst() int
+	// WaitN blocks until n more messages may be published, or the context is done
+	WaitN(ctx context.Context, n int) error
+}
+
+// Paced publishes the messages of the given topic tokens in batches no larger than the pacer's burst, waiting for the
+// pacer to allow each batch. This bounds the rate messages reach the broker, however many a single emit buffers.
+// Messages of other topics are published unpaced. Should a batch fail, the batches before it remain published.
+func Paced(ctx context.Context, pacer Pacer, tokens ...string) func(p Provider) Provider {
+	return func(p Provider) Provider {
+		return func(token string) producer.MessageProducer {
+			mp := p(token)
+			if !slices.Contains(tokens, token) {
+				return mp
+			}
+			return func(provider model.Provider[[]kafka.Message]) error {
+				ms, err := provider()
+				if err != nil {
+					return err
+				}
+				for len(ms) > 0 {
+					n := min(len(ms), max(pacer.Burst(), 1))
+					if err = pacer.WaitN(ctx, n); err != nil {
+						return err
+					}
+					if err = mp(model.FixedProvider(ms[:n])); err != nil {
+						return err
+					}
+					ms = ms[n:]
+				}
+				return nil
+			}
+		}
+	}
+}
@@ -1,20 +1,89 @@
 package producer
 
 import (
+	"atlas-transports/metrics"
+	"atlas-transports/tracing"
 	"context"
+	"time"
+
 	"github.com/Chronicle20/atlas-kafka/producer"
 	"github.com/Chronicle20/atlas-kafka/topic"
+	"github.com/Chronicle20/atlas-model/model"
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/segmentio/kafka-go"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Provider func(token string) producer.MessageProducer
 
 func ProviderImpl(l logrus.FieldLogger) func(ctx context.Context) func(token string) producer.MessageProducer {
 	return func(ctx context.Context) func(token string) producer.MessageProducer {
-		sd := producer.SpanHeaderDecorator(ctx)
-		td := producer.TenantHeaderDecorator(ctx)
 		return func(token string) producer.MessageProducer {
-			return producer.Produce(l)(producer.WriterProvider(topic.EnvProvider(l)(token)))(sd, td)
+			wp := producer.WriterProvider(topic.EnvProvider(l)(token))
+			return idempotentProducer(l, ctx)(instrumentedProducer(l, ctx, token)(func(sctx context.Context) producer.MessageProducer {
+				return producer.Produce(l)(wp)(producer.SpanHeaderDecorator(sctx), producer.TenantHeaderDecorator(sctx))
+			}))
+		}
+	}
+}
+
+// instrumentedProducer traces produce calls and counts failures against the topic, and records the outcome for health
+// checks. The messages are produced under the produce call's span, so that their headers link consumers to it.
+func instrumentedProducer(l logrus.FieldLogger, ctx context.Context, token string) func(mp func(ctx context.Context) producer.MessageProducer) producer.MessageProducer {
+	return func(mp func(ctx context.Context) producer.MessageProducer) producer.MessageProducer {
+		return func(provider model.Provider[[]kafka.Message]) error {
+			_, sctx, span := tracing.StartSpan(l, ctx, "kafka.publish", trace.WithSpanKind(trace.SpanKindProducer), trace.WithAttributes(attribute.String("messaging.destination.name", token)))
+			err := mp(sctx)(provider)
+			tracing.EndSpan(span, err)
+			if err != nil {
+				metrics.PublishError(token)
+			}
+			recordPublish(time.Now(), err)
+			return err
+		}
+	}
+}
+
+// idempotentProducer drops messages whose idempotency key was already published, or is being published by another
+// call. Keys are reserved before the messages are produced, so concurrent calls cannot both publish the same key. They
+// are recorded once the messages have been produced successfully, and released if producing fails.
+func idempotentProducer(l logrus.FieldLogger, ctx context.Context) func(mp producer.MessageProducer) producer.MessageProducer {
+	return func(mp producer.MessageProducer) producer.MessageProducer {
+		return func(provider model.Provider[[]kafka.Message]) error {
+			ms, err := provider()
+			if err != nil {
+				return err
+			}
+
+			tenantId := tenant.MustFromContext(ctx).Id()
+			var keys []string
+			var results []kafka.Message
+			for _, m := range ms {
+				key, ok := idempotencyKey(m)
+				if !ok {
+					results = append(results, m)
+					continue
+				}
+				if !getIdempotencyRegistry().Reserve(tenantId, key) {
+					l.Debugf("Suppressing duplicate message with idempotency key [%s].", key)
+					continue
+				}
+				keys = append(keys, key)
+				results = append(results, m)
+			}
+			if len(results) == 0 {
+				return nil
+			}
+
+			err = mp(model.FixedProvider(results))
+			if err != nil {
+				getIdempotencyRegistry().Release(tenantId, keys...)
+				return err
+			}
+			getIdempotencyRegistry().Record(tenantId, keys...)
+			return nil
 		}
 	}
 }
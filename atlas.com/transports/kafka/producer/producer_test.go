@@ -0,0 +1,110 @@
+package producer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Chronicle20/atlas-kafka/producer"
+	"github.com/Chronicle20/atlas-model/model"
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func keyedMessages(key string) model.Provider[[]kafka.Message] {
+	return WithIdempotencyKey(key)(model.FixedProvider([]kafka.Message{{Value: []byte("{}")}}))
+}
+
+func TestIdempotentProducer_ReleasesKeyOnFailure(t *testing.T) {
+	l, _ := test.NewNullLogger()
+	ten, _ := tenant.Register(uuid.New(), "GMS", 83, 1)
+	ctx := tenant.WithContext(context.Background(), ten)
+
+	var published int
+	fail := true
+	mp := idempotentProducer(l, ctx)(func(p model.Provider[[]kafka.Message]) error {
+		if fail {
+			return errors.New("broker unavailable")
+		}
+		ms, _ := p()
+		published += len(ms)
+		return nil
+	})
+
+	assert.Error(t, mp(keyedMessages("trip@20230101:1:departure")))
+	assert.False(t, IdempotencyKeyPublished(ten.Id(), "trip@20230101:1:departure"))
+
+	fail = false
+	assert.NoError(t, mp(keyedMessages("trip@20230101:1:departure")))
+	assert.NoError(t, mp(keyedMessages("trip@20230101:1:departure")))
+	assert.Equal(t, 1, published)
+	assert.True(t, IdempotencyKeyPublished(ten.Id(), "trip@20230101:1:departure"))
+}
+
+func TestIdempotentProducer_ConcurrentPublishesOfKeyAreExclusive(t *testing.T) {
+	l, _ := test.NewNullLogger()
+	ten, _ := tenant.Register(uuid.New(), "GMS", 83, 1)
+	ctx := tenant.WithContext(context.Background(), ten)
+
+	var published atomic.Int32
+	release := make(chan struct{})
+	mp := idempotentProducer(l, ctx)(func(p model.Provider[[]kafka.Message]) error {
+		<-release
+		ms, _ := p()
+		published.Add(int32(len(ms)))
+		return nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = mp(keyedMessages("trip@20230101:1:arrival"))
+		}()
+	}
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), published.Load())
+}
+
+type countingPacer struct {
+	burst int
+	waits []int
+}
+
+func (p *countingPacer) Burst() int {
+	return p.burst
+}
+
+func (p *countingPacer) WaitN(_ context.Context, n int) error {
+	p.waits = append(p.waits, n)
+	return nil
+}
+
+func TestPaced_PublishesInBurstSizedBatches(t *testing.T) {
+	var batches []int
+	p := Provider(func(token string) producer.MessageProducer {
+		return func(provider model.Provider[[]kafka.Message]) error {
+			ms, _ := provider()
+			batches = append(batches, len(ms))
+			return nil
+		}
+	})
+	pacer := &countingPacer{burst: 4}
+	paced := Paced(context.Background(), pacer, "COMMAND_TOPIC_CHARACTER")(p)
+
+	assert.NoError(t, paced("COMMAND_TOPIC_CHARACTER")(model.FixedProvider(make([]kafka.Message, 10))))
+	assert.Equal(t, []int{4, 4, 2}, batches)
+	assert.Equal(t, []int{4, 4, 2}, pacer.waits)
+
+	batches = nil
+	assert.NoError(t, paced("EVENT_TOPIC_TRANSPORT_STATUS")(model.FixedProvider(make([]kafka.Message, 10))))
+	assert.Equal(t, []int{10}, batches)
+	assert.Equal(t, []int{4, 4, 2}, pacer.waits)
+}
@@ -0,0 +1,52 @@
+package producer
+
+import (
+	"sync"
+	"time"
+)
+
+// PublishWindow is how far back produce calls are counted for health checks.
+const PublishWindow = time.Minute
+
+// publishBucket counts the produce calls made within a single second.
+type publishBucket struct {
+	second   int64
+	attempts int
+	failures int
+}
+
+var publishStatus struct {
+	mu      sync.Mutex
+	buckets [int(PublishWindow / time.Second)]publishBucket
+	lastErr error
+}
+
+func recordPublish(now time.Time, err error) {
+	publishStatus.mu.Lock()
+	defer publishStatus.mu.Unlock()
+	second := now.Unix()
+	b := &publishStatus.buckets[second%int64(len(publishStatus.buckets))]
+	if b.second != second {
+		*b = publishBucket{second: second}
+	}
+	b.attempts++
+	if err != nil {
+		b.failures++
+		publishStatus.lastErr = err
+	}
+}
+
+// PublishStatus returns how many produce calls were made, and how many of them failed, within the window ending now,
+// along with the error of the most recent failure.
+func PublishStatus(now time.Time) (attempts int, failures int, lastErr error) {
+	publishStatus.mu.Lock()
+	defer publishStatus.mu.Unlock()
+	oldest := now.Add(-PublishWindow).Unix()
+	for _, b := range publishStatus.buckets {
+		if b.second > oldest && b.second <= now.Unix() {
+			attempts += b.attempts
+			failures += b.failures
+		}
+	}
+	return attempts, failures, publishStatus.lastErr
+}
@@ -0,0 +1,29 @@
+package producer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishStatus_CountsCallsWithinWindow(t *testing.T) {
+	start := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	recordPublish(start, errors.New("broker unavailable"))
+	recordPublish(start.Add(10*time.Second), nil)
+	recordPublish(start.Add(20*time.Second), errors.New("leader not available"))
+
+	attempts, failures, err := PublishStatus(start.Add(30 * time.Second))
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, 2, failures)
+	assert.EqualError(t, err, "leader not available")
+
+	attempts, failures, _ = PublishStatus(start.Add(PublishWindow + 15*time.Second))
+	assert.Equal(t, 1, attempts)
+	assert.Equal(t, 1, failures)
+
+	attempts, failures, _ = PublishStatus(start.Add(2 * PublishWindow))
+	assert.Equal(t, 0, attempts)
+	assert.Equal(t, 0, failures)
+}
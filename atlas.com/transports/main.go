@@ -1,9 +1,13 @@
 package main
 
 import (
+	channel2 "atlas-transports/channel"
+	"atlas-transports/clock"
+	"atlas-transports/health"
 	"atlas-transports/kafka/consumer/channel"
 	"atlas-transports/kafka/consumer/character"
 	"atlas-transports/logger"
+	"atlas-transports/metrics"
 	"atlas-transports/service"
 	tenant2 "atlas-transports/tenant"
 	"atlas-transports/tracing"
@@ -12,7 +16,10 @@ import (
 	"github.com/Chronicle20/atlas-kafka/consumer"
 	"github.com/Chronicle20/atlas-rest/server"
 	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/sirupsen/logrus"
 	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -56,6 +63,10 @@ func main() {
 	channel.InitHandlers(l)(consumer.GetManager().RegisterHandler)
 	character.InitHandlers(l)(consumer.GetManager().RegisterHandler)
 
+	// Serve metrics and health checks while starting up, so that liveness and readiness can be probed
+	health.WatchConsumerGroup(consumerGroupId)
+	metrics.Serve(l, tdm.Context(), tdm.WaitGroup(), health.InitHandlers(l))
+
 	tenants, err := tenant2.NewProcessor(l, tdm.Context()).GetAll()
 	if err != nil {
 		l.WithError(err).Fatal("Unable to load tenants.")
@@ -71,7 +82,14 @@ func main() {
 			routes = []transport.Model{}
 			sharedVessels = []transport.SharedVesselModel{}
 		}
-		_ = transport.NewProcessor(l, ctx).AddTenant(routes, sharedVessels)
+		tp := transport.NewProcessor(l, ctx)
+		_ = tp.AddTenant(routes, sharedVessels)
+		scheduled, _ := tp.AllRoutesProvider()()
+		quarantined, _ := tp.QuarantineProvider()()
+		health.ConfigurationLoaded(t, len(scheduled), len(quarantined), err)
+
+		// Rebuild the channel registry from running channels, rather than waiting for them to restart
+		_ = channel2.NewProcessor(l, ctx).Bootstrap()
 	}
 
 	// Start a background goroutine to periodically update route states
@@ -84,8 +102,68 @@ func main() {
 			case <-tdm.Context().Done():
 				return
 			case <-ticker.C:
+				// The heartbeat is only recorded once the route updates of every tenant have finished
+				var wg sync.WaitGroup
+				var failed atomic.Bool
 				for _, t := range tenants {
-					transport.NewProcessor(l, tenant.WithContext(tdm.Context(), t)).UpdateRoutes()
+					wg.Add(1)
+					go func(t tenant.Model) {
+						defer wg.Done()
+						if err := transport.NewProcessor(l, tenant.WithContext(tdm.Context(), t)).UpdateRoutes(); err != nil {
+							failed.Store(true)
+						}
+					}(t)
+				}
+				wg.Wait()
+				if !failed.Load() {
+					health.Beat()
+				}
+			}
+		}
+	}()
+
+	// Start a background goroutine to recover passengers stranded in route maps, once the route states have settled
+	// after startup and periodically thereafter
+	go func() {
+		sweep := func() {
+			for _, t := range tenants {
+				_ = transport.NewProcessor(l, tenant.WithContext(tdm.Context(), t)).RecoverStrandedPassengersAndEmit()
+			}
+		}
+
+		select {
+		case <-tdm.Context().Done():
+			return
+		case <-time.After(5 * time.Second):
+			sweep()
+		}
+
+		ticker := time.NewTicker(recoverySweepInterval(l))
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-tdm.Context().Done():
+				return
+			case <-ticker.C:
+				sweep()
+			}
+		}
+	}()
+
+	// Start a background goroutine to periodically reconcile the locally tracked occupancy of route maps with the MAPS
+	// service
+	go func() {
+		ticker := time.NewTicker(occupancyReconcileInterval(l))
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-tdm.Context().Done():
+				return
+			case <-ticker.C:
+				for _, t := range tenants {
+					_ = transport.NewProcessor(l, tenant.WithContext(tdm.Context(), t)).ReconcileOccupancy()
 				}
 			}
 		}
@@ -98,6 +176,8 @@ func main() {
 		SetBasePath(GetServer().GetPrefix()).
 		SetPort(os.Getenv("REST_PORT")).
 		AddRouteInitializer(transport.InitResource(GetServer())).
+		AddRouteInitializer(channel2.InitResource(GetServer())).
+		AddRouteInitializer(clock.InitResource(GetServer())).
 		Run()
 
 	tdm.TeardownFunc(tracing.Teardown(l)(tc))
@@ -105,3 +185,23 @@ func main() {
 	tdm.Wait()
 	l.Infoln("Service shutdown.")
 }
+
+func recoverySweepInterval(l logrus.FieldLogger) time.Duration {
+	if val, ok := os.LookupEnv("RECOVERY_SWEEP_INTERVAL"); ok {
+		if d, err := time.ParseDuration(val); err == nil && d > 0 {
+			return d
+		}
+		l.Warnf("Invalid RECOVERY_SWEEP_INTERVAL [%s], using default.", val)
+	}
+	return 1 * time.Minute
+}
+
+func occupancyReconcileInterval(l logrus.FieldLogger) time.Duration {
+	if val, ok := os.LookupEnv("OCCUPANCY_RECONCILE_INTERVAL"); ok {
+		if d, err := time.ParseDuration(val); err == nil && d > 0 {
+			return d
+		}
+		l.Warnf("Invalid OCCUPANCY_RECONCILE_INTERVAL [%s], using default.", val)
+	}
+	return 5 * time.Minute
+}
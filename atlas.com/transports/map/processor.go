@@ -1,32 +1,160 @@
 package _map
 
 import (
+	"atlas-transports/metrics"
+	"atlas-transports/throttle"
+	"atlas-transports/tracing"
 	"context"
 	"github.com/Chronicle20/atlas-constants/channel"
+	"github.com/Chronicle20/atlas-constants/field"
 	_map2 "github.com/Chronicle20/atlas-constants/map"
 	"github.com/Chronicle20/atlas-constants/world"
 	"github.com/Chronicle20/atlas-model/model"
 	"github.com/Chronicle20/atlas-rest/requests"
+	tenant "github.com/Chronicle20/atlas-tenant"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"sync"
+	"time"
 )
 
 type Processor interface {
 	CharacterIdsInMapProvider(worldId world.Id, channelId channel.Id, mapId _map2.Id) model.Provider[[]uint32]
+	CharacterIdsInChannelMapsProvider(worldId world.Id, channelId channel.Id, mapIds []_map2.Id) model.Provider[map[_map2.Id][]uint32]
+	CharacterIdsInFieldsProvider(fields []field.Model) model.Provider[map[field.Id][]uint32]
 }
 
 type ProcessorImpl struct {
 	l   logrus.FieldLogger
 	ctx context.Context
+	t   tenant.Model
 }
 
 func NewProcessor(l logrus.FieldLogger, ctx context.Context) Processor {
 	p := &ProcessorImpl{
 		l:   l,
 		ctx: ctx,
+		t:   tenant.MustFromContext(ctx),
 	}
 	return p
 }
 
 func (p *ProcessorImpl) CharacterIdsInMapProvider(worldId world.Id, channelId channel.Id, mapId _map2.Id) model.Provider[[]uint32] {
-	return requests.SliceProvider[RestModel, uint32](p.l, p.ctx)(requestCharactersInMap(worldId, channelId, mapId), Extract, model.Filters[uint32]())
+	return func() ([]uint32, error) {
+		start := time.Now()
+		sl, sctx, span := tracing.StartSpan(p.l, p.ctx, "maps.characters_in_map", trace.WithAttributes(
+			attribute.Int("world.id", int(worldId)),
+			attribute.Int("channel.id", int(channelId)),
+			attribute.Int64("map.id", int64(mapId)),
+		))
+		ids, err := requests.SliceProvider[RestModel, uint32](sl, sctx)(requestCharactersInMap(worldId, channelId, mapId), Extract, model.Filters[uint32]())()
+		tracing.EndSpan(span, err)
+		metrics.Request("maps", time.Since(start), err)
+		return ids, err
+	}
+}
+
+// CharacterIdsInChannelMapsProvider returns the characters in each of the maps of a channel, keyed by map id, with a
+// single request to the MAPS service.
+func (p *ProcessorImpl) CharacterIdsInChannelMapsProvider(worldId world.Id, channelId channel.Id, mapIds []_map2.Id) model.Provider[map[_map2.Id][]uint32] {
+	return func() (map[_map2.Id][]uint32, error) {
+		start := time.Now()
+		sl, sctx, span := tracing.StartSpan(p.l, p.ctx, "maps.characters_in_maps", trace.WithAttributes(
+			attribute.Int("world.id", int(worldId)),
+			attribute.Int("channel.id", int(channelId)),
+			attribute.Int("map.count", len(mapIds)),
+		))
+		rms, err := requestCharactersInMaps(worldId, channelId, mapIds)(sl, sctx)
+		tracing.EndSpan(span, err)
+		metrics.Request("maps", time.Since(start), err)
+		if err != nil {
+			return nil, err
+		}
+		results := make(map[_map2.Id][]uint32)
+		for _, mapId := range mapIds {
+			results[mapId] = make([]uint32, 0)
+		}
+		for _, rm := range rms {
+			if _, ok := results[rm.MapId]; !ok {
+				continue
+			}
+			id, err := Extract(RestModel{Id: rm.Id})
+			if err != nil {
+				return nil, err
+			}
+			results[rm.MapId] = append(results[rm.MapId], id)
+		}
+		return results, nil
+	}
+}
+
+// channelMaps are the maps of a single channel queried together.
+type channelMaps struct {
+	worldId   world.Id
+	channelId channel.Id
+	mapIds    []_map2.Id
+}
+
+// CharacterIdsInFieldsProvider returns the characters in each of the fields, keyed by field id. Fields of the same
+// channel are queried in one batch request, falling back to a request per map should the batch fail. Duplicate fields
+// are queried once, and the queries share the tenant's bounded worker pool so large tenants do not burst the MAPS
+// service.
+func (p *ProcessorImpl) CharacterIdsInFieldsProvider(fields []field.Model) model.Provider[map[field.Id][]uint32] {
+	return func() (map[field.Id][]uint32, error) {
+		seen := make(map[field.Id]bool)
+		byChannel := make(map[channel.Model]*channelMaps)
+		var queries []*channelMaps
+		for _, f := range fields {
+			if seen[f.Id()] {
+				continue
+			}
+			seen[f.Id()] = true
+			key := channel.NewModel(f.WorldId(), f.ChannelId())
+			q, ok := byChannel[key]
+			if !ok {
+				q = &channelMaps{worldId: f.WorldId(), channelId: f.ChannelId()}
+				byChannel[key] = q
+				queries = append(queries, q)
+			}
+			q.mapIds = append(q.mapIds, f.MapId())
+		}
+
+		var mu sync.Mutex
+		results := make(map[field.Id][]uint32)
+		err := throttle.ForEach(throttle.ForTenant(p.l, p.t), queries, func(q *channelMaps) error {
+			byMap, err := p.CharacterIdsInChannelMapsProvider(q.worldId, q.channelId, q.mapIds)()
+			if err != nil {
+				p.l.WithError(err).Warnf("Unable to retrieve characters in [%d] maps of world [%d] channel [%d] at once, querying each map.", len(q.mapIds), q.worldId, q.channelId)
+				byMap, err = p.characterIdsInEachMap(q)
+				if err != nil {
+					return err
+				}
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for mapId, ids := range byMap {
+				results[field.NewBuilder(q.worldId, q.channelId, mapId).Build().Id()] = ids
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return results, nil
+	}
+}
+
+// characterIdsInEachMap queries the characters of the maps of a channel one map at a time.
+func (p *ProcessorImpl) characterIdsInEachMap(q *channelMaps) (map[_map2.Id][]uint32, error) {
+	results := make(map[_map2.Id][]uint32)
+	for _, mapId := range q.mapIds {
+		ids, err := p.CharacterIdsInMapProvider(q.worldId, q.channelId, mapId)()
+		if err != nil {
+			p.l.WithError(err).Errorf("Unable to retrieve characters in map [%d] of world [%d] channel [%d].", mapId, q.worldId, q.channelId)
+			return nil, err
+		}
+		results[mapId] = ids
+	}
+	return results, nil
 }
@@ -7,11 +7,14 @@ import (
 	_map "github.com/Chronicle20/atlas-constants/map"
 	"github.com/Chronicle20/atlas-constants/world"
 	"github.com/Chronicle20/atlas-rest/requests"
+	"strconv"
+	"strings"
 )
 
 const (
-	mapResource           = "worlds/%d/channels/%d/maps/%d"
-	mapCharactersResource = mapResource + "/characters/"
+	mapResource                     = "worlds/%d/channels/%d/maps/%d"
+	mapCharactersResource           = mapResource + "/characters/"
+	channelCharactersInMapsResource = "worlds/%d/channels/%d/characters?filter[mapIds]=%s"
 )
 
 func getBaseRequest() string {
@@ -21,3 +24,11 @@ func getBaseRequest() string {
 func requestCharactersInMap(worldId world.Id, channelId channel.Id, mapId _map.Id) requests.Request[[]RestModel] {
 	return rest.MakeGetRequest[[]RestModel](fmt.Sprintf(getBaseRequest()+mapCharactersResource, worldId, channelId, mapId))
 }
+
+func requestCharactersInMaps(worldId world.Id, channelId channel.Id, mapIds []_map.Id) requests.Request[[]CharacterRestModel] {
+	ids := make([]string, 0, len(mapIds))
+	for _, mapId := range mapIds {
+		ids = append(ids, strconv.Itoa(int(mapId)))
+	}
+	return rest.MakeGetRequest[[]CharacterRestModel](fmt.Sprintf(getBaseRequest()+channelCharactersInMapsResource, worldId, channelId, strings.Join(ids, ",")))
+}
@@ -1,6 +1,10 @@
 package _map
 
-import "strconv"
+import (
+	"strconv"
+
+	_map "github.com/Chronicle20/atlas-constants/map"
+)
 
 type RestModel struct {
 	Id string `json:"-"`
@@ -26,3 +30,22 @@ func Extract(m RestModel) (uint32, error) {
 	}
 	return uint32(id), nil
 }
+
+// CharacterRestModel is a character in a channel, along with the map it is in
+type CharacterRestModel struct {
+	Id    string  `json:"-"`
+	MapId _map.Id `json:"mapId"`
+}
+
+func (r CharacterRestModel) GetName() string {
+	return "characters"
+}
+
+func (r CharacterRestModel) GetID() string {
+	return r.Id
+}
+
+func (r *CharacterRestModel) SetID(idStr string) error {
+	r.Id = idStr
+	return nil
+}
@@ -0,0 +1,105 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "atlas_transports"
+
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+var (
+	routeState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "route_state",
+		Help:      "Current state of each route, 1 for the state the route is in and 0 otherwise.",
+	}, []string{"tenant", "route", "state"})
+
+	transitions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transitions_total",
+		Help:      "Route transitions attempted, by outcome.",
+	}, []string{"tenant", "route", "from", "to", "result"})
+
+	transitionLag = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "transition_lag_seconds",
+		Help:      "Delay between the scheduled time of a committed transition and the tick which detected it.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2, 5, 10, 30, 60, 300},
+	}, []string{"tenant", "route"})
+
+	warps = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "warps_total",
+		Help:      "Passenger warps issued, by leg and outcome.",
+	}, []string{"tenant", "leg", "result"})
+
+	tickDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "tick_duration_seconds",
+		Help:      "Time taken to update every route of a tenant, up to the deadline of a pass.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"tenant"})
+
+	requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "dependency_request_duration_seconds",
+		Help:      "Latency of requests made to other services, by service and outcome.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"service", "result"})
+
+	publishErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "kafka_publish_errors_total",
+		Help:      "Kafka produce calls which failed, by topic.",
+	}, []string{"topic"})
+)
+
+func result(err error) string {
+	if err != nil {
+		return ResultFailure
+	}
+	return ResultSuccess
+}
+
+// RouteState records the state a route is in. Every other known state of the route is reset to 0.
+func RouteState(tenantId string, routeId string, state string, states []string) {
+	for _, s := range states {
+		v := 0.0
+		if s == state {
+			v = 1
+		}
+		routeState.WithLabelValues(tenantId, routeId, s).Set(v)
+	}
+}
+
+// Transition records the outcome of a transition attempt. The lag is only observed for committed transitions.
+func Transition(tenantId string, routeId string, from string, to string, lag time.Duration, err error) {
+	transitions.WithLabelValues(tenantId, routeId, from, to, result(err)).Inc()
+	if err == nil {
+		transitionLag.WithLabelValues(tenantId, routeId).Observe(lag.Seconds())
+	}
+}
+
+func Warp(tenantId string, leg string, err error) {
+	warps.WithLabelValues(tenantId, leg, result(err)).Inc()
+}
+
+func Tick(tenantId string, d time.Duration) {
+	tickDuration.WithLabelValues(tenantId).Observe(d.Seconds())
+}
+
+// Request records the latency of a request made to another service.
+func Request(service string, d time.Duration, err error) {
+	requestDuration.WithLabelValues(service, result(err)).Observe(d.Seconds())
+}
+
+func PublishError(topic string) {
+	publishErrors.WithLabelValues(topic).Inc()
+}
@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransition_ObservesLagOnlyWhenCommitted(t *testing.T) {
+	Transition("tenant-a", "route-a", "open_entry", "locked_entry", 2*time.Second, errors.New("broker unavailable"))
+	assert.Equal(t, 1.0, testutil.ToFloat64(transitions.WithLabelValues("tenant-a", "route-a", "open_entry", "locked_entry", ResultFailure)))
+	assert.Equal(t, 0, testutil.CollectAndCount(transitionLag, "atlas_transports_transition_lag_seconds"))
+
+	Transition("tenant-a", "route-a", "open_entry", "locked_entry", 2*time.Second, nil)
+	assert.Equal(t, 1.0, testutil.ToFloat64(transitions.WithLabelValues("tenant-a", "route-a", "open_entry", "locked_entry", ResultSuccess)))
+	assert.Equal(t, 1, testutil.CollectAndCount(transitionLag, "atlas_transports_transition_lag_seconds"))
+}
+
+func TestRouteState_ResetsOtherStates(t *testing.T) {
+	states := []string{"awaiting_return", "open_entry", "locked_entry", "in_transit"}
+	RouteState("tenant-b", "route-b", "open_entry", states)
+	RouteState("tenant-b", "route-b", "locked_entry", states)
+
+	assert.Equal(t, 0.0, testutil.ToFloat64(routeState.WithLabelValues("tenant-b", "route-b", "open_entry")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(routeState.WithLabelValues("tenant-b", "route-b", "locked_entry")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(routeState.WithLabelValues("tenant-b", "route-b", "in_transit")))
+}
+
+func TestWarp_CountsByLegAndOutcome(t *testing.T) {
+	Warp("tenant-c", "departure", nil)
+	Warp("tenant-c", "departure", nil)
+	Warp("tenant-c", "departure", errors.New("broker unavailable"))
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(warps.WithLabelValues("tenant-c", "departure", ResultSuccess)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(warps.WithLabelValues("tenant-c", "departure", ResultFailure)))
+}
@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sirupsen/logrus"
+)
+
+const defaultPort = "9100"
+
+// Serve exposes the metrics at /metrics on METRICS_PORT until the context is cancelled. Other operational endpoints,
+// such as health checks, are registered on the same listener through the initializers.
+func Serve(l logrus.FieldLogger, ctx context.Context, wg *sync.WaitGroup, initializers ...func(mux *http.ServeMux)) {
+	port, ok := os.LookupEnv("METRICS_PORT")
+	if !ok || port == "" {
+		port = defaultPort
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	for _, i := range initializers {
+		i(mux)
+	}
+	srv := &http.Server{Addr: ":" + port, Handler: mux}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		l.Infof("Serving metrics on port [%s].", port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			l.WithError(err).Error("Metrics server stopped unexpectedly.")
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		sctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(sctx)
+	}()
+}
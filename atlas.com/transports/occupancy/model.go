@@ -0,0 +1,26 @@
+package occupancy
+
+import (
+	"github.com/Chronicle20/atlas-constants/field"
+)
+
+// Model is the set of characters known to be in a field
+type Model struct {
+	field        field.Model
+	characterIds []uint32
+}
+
+func NewModel(f field.Model, characterIds []uint32) Model {
+	return Model{
+		field:        f,
+		characterIds: characterIds,
+	}
+}
+
+func (m Model) Field() field.Model {
+	return m.field
+}
+
+func (m Model) CharacterIds() []uint32 {
+	return m.characterIds
+}
@@ -0,0 +1,107 @@
+package occupancy
+
+import (
+	_map "atlas-transports/map"
+	"context"
+	"github.com/Chronicle20/atlas-constants/field"
+	map2 "github.com/Chronicle20/atlas-constants/map"
+	"github.com/Chronicle20/atlas-model/model"
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+type Processor interface {
+	Track(mapIds ...map2.Id)
+	Enter(characterId uint32, f field.Model) error
+	Leave(characterId uint32) error
+	Reconcile(fields []field.Model) error
+	CharacterIdsInFieldsProvider(fields []field.Model) model.Provider[map[field.Id][]uint32]
+	ByFieldsProvider(fields []field.Model) model.Provider[[]Model]
+}
+
+type ProcessorImpl struct {
+	l   logrus.FieldLogger
+	ctx context.Context
+	t   tenant.Model
+	mp  _map.Processor
+}
+
+func NewProcessor(l logrus.FieldLogger, ctx context.Context) Processor {
+	return &ProcessorImpl{
+		l:   l,
+		ctx: ctx,
+		t:   tenant.MustFromContext(ctx),
+		mp:  _map.NewProcessor(l, ctx),
+	}
+}
+
+func (p *ProcessorImpl) Track(mapIds ...map2.Id) {
+	getRegistry().Track(p.t.Id(), mapIds...)
+}
+
+func (p *ProcessorImpl) Enter(characterId uint32, f field.Model) error {
+	getRegistry().Enter(p.t.Id(), characterId, f)
+	return nil
+}
+
+func (p *ProcessorImpl) Leave(characterId uint32) error {
+	getRegistry().Leave(p.t.Id(), characterId)
+	return nil
+}
+
+// Reconcile replaces the recorded contents of the fields with those reported by the MAPS service.
+func (p *ProcessorImpl) Reconcile(fields []field.Model) error {
+	results, err := p.mp.CharacterIdsInFieldsProvider(fields)()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, f := range fields {
+		getRegistry().Reconcile(p.t.Id(), f, results[f.Id()], now)
+	}
+	return nil
+}
+
+// CharacterIdsInFieldsProvider returns the characters in each of the fields, keyed by field id. Fields which have
+// been reconciled are served from the registry, and the MAPS service is queried only for the rest.
+func (p *ProcessorImpl) CharacterIdsInFieldsProvider(fields []field.Model) model.Provider[map[field.Id][]uint32] {
+	return func() (map[field.Id][]uint32, error) {
+		results := make(map[field.Id][]uint32)
+		var unknown []field.Model
+		for _, f := range fields {
+			if characterIds, ok := getRegistry().Get(p.t.Id(), f); ok {
+				results[f.Id()] = characterIds
+			} else {
+				unknown = append(unknown, f)
+			}
+		}
+		if len(unknown) == 0 {
+			return results, nil
+		}
+
+		p.l.Debugf("Querying MAPS for [%d] fields not yet reconciled.", len(unknown))
+		err := p.Reconcile(unknown)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range unknown {
+			results[f.Id()], _ = getRegistry().Get(p.t.Id(), f)
+		}
+		return results, nil
+	}
+}
+
+func (p *ProcessorImpl) ByFieldsProvider(fields []field.Model) model.Provider[[]Model] {
+	return func() ([]Model, error) {
+		results, err := p.CharacterIdsInFieldsProvider(fields)()
+		if err != nil {
+			return nil, err
+		}
+		var ms []Model
+		for _, f := range fields {
+			ms = append(ms, NewModel(f, results[f.Id()]))
+		}
+		return ms, nil
+	}
+}
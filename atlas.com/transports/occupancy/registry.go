@@ -0,0 +1,132 @@
+package occupancy
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Chronicle20/atlas-constants/field"
+	_map "github.com/Chronicle20/atlas-constants/map"
+	"github.com/google/uuid"
+)
+
+// Registry holds the characters in each route-related field of each tenant. Only maps which have been tracked are
+// recorded. A field's contents are authoritative once it has been reconciled against the MAPS service, and are kept
+// current from character status events thereafter.
+type Registry struct {
+	mu         sync.RWMutex
+	tracked    map[uuid.UUID]map[_map.Id]struct{}
+	fields     map[uuid.UUID]map[field.Id]map[uint32]struct{}
+	locations  map[uuid.UUID]map[uint32]field.Id
+	reconciled map[uuid.UUID]map[field.Id]time.Time
+}
+
+var registry *Registry
+var registryOnce sync.Once
+
+func getRegistry() *Registry {
+	registryOnce.Do(func() {
+		registry = &Registry{
+			tracked:    make(map[uuid.UUID]map[_map.Id]struct{}),
+			fields:     make(map[uuid.UUID]map[field.Id]map[uint32]struct{}),
+			locations:  make(map[uuid.UUID]map[uint32]field.Id),
+			reconciled: make(map[uuid.UUID]map[field.Id]time.Time),
+		}
+	})
+	return registry
+}
+
+// Track records the maps whose occupancy is of interest to the tenant.
+func (r *Registry) Track(tenantId uuid.UUID, mapIds ..._map.Id) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tracked[tenantId]; !ok {
+		r.tracked[tenantId] = make(map[_map.Id]struct{})
+	}
+	for _, mapId := range mapIds {
+		r.tracked[tenantId][mapId] = struct{}{}
+	}
+}
+
+func (r *Registry) IsTracked(tenantId uuid.UUID, mapId _map.Id) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.tracked[tenantId][mapId]
+	return ok
+}
+
+// Enter records the character as being in the field, and no longer in whichever field they were in before. Characters
+// entering untracked maps are only removed from their previous field.
+func (r *Registry) Enter(tenantId uuid.UUID, characterId uint32, f field.Model) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.leave(tenantId, characterId)
+	if _, ok := r.tracked[tenantId][f.MapId()]; !ok {
+		return
+	}
+	r.add(tenantId, characterId, f.Id())
+}
+
+// Leave removes the character from whichever field they were in.
+func (r *Registry) Leave(tenantId uuid.UUID, characterId uint32) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.leave(tenantId, characterId)
+}
+
+// Reconcile replaces the contents of the field with the characters reported by the MAPS service.
+func (r *Registry) Reconcile(tenantId uuid.UUID, f field.Model, characterIds []uint32, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, ok := r.fields[tenantId][f.Id()]; ok {
+		for characterId := range existing {
+			r.leave(tenantId, characterId)
+		}
+	}
+	for _, characterId := range characterIds {
+		r.leave(tenantId, characterId)
+		r.add(tenantId, characterId, f.Id())
+	}
+	if _, ok := r.reconciled[tenantId]; !ok {
+		r.reconciled[tenantId] = make(map[field.Id]time.Time)
+	}
+	r.reconciled[tenantId][f.Id()] = now
+}
+
+// Get returns the characters in the field, and whether the field has been reconciled and can be trusted.
+func (r *Registry) Get(tenantId uuid.UUID, f field.Model) ([]uint32, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.reconciled[tenantId][f.Id()]
+	characterIds := make([]uint32, 0, len(r.fields[tenantId][f.Id()]))
+	for characterId := range r.fields[tenantId][f.Id()] {
+		characterIds = append(characterIds, characterId)
+	}
+	return characterIds, ok
+}
+
+func (r *Registry) add(tenantId uuid.UUID, characterId uint32, fieldId field.Id) {
+	if _, ok := r.fields[tenantId]; !ok {
+		r.fields[tenantId] = make(map[field.Id]map[uint32]struct{})
+	}
+	if _, ok := r.fields[tenantId][fieldId]; !ok {
+		r.fields[tenantId][fieldId] = make(map[uint32]struct{})
+	}
+	r.fields[tenantId][fieldId][characterId] = struct{}{}
+
+	if _, ok := r.locations[tenantId]; !ok {
+		r.locations[tenantId] = make(map[uint32]field.Id)
+	}
+	r.locations[tenantId][characterId] = fieldId
+}
+
+func (r *Registry) leave(tenantId uuid.UUID, characterId uint32) {
+	fieldId, ok := r.locations[tenantId][characterId]
+	if !ok {
+		return
+	}
+	delete(r.locations[tenantId], characterId)
+	delete(r.fields[tenantId][fieldId], characterId)
+	if len(r.fields[tenantId][fieldId]) == 0 {
+		delete(r.fields[tenantId], fieldId)
+	}
+}
@@ -0,0 +1,41 @@
+package occupancy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Chronicle20/atlas-constants/field"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_TracksMovementBetweenFields(t *testing.T) {
+	tenantId := uuid.New()
+	staging := field.NewBuilder(0, 1, 101000301).Build()
+	enRoute := field.NewBuilder(0, 1, 200090000).Build()
+	elsewhere := field.NewBuilder(0, 1, 100000000).Build()
+	r := getRegistry()
+	r.Track(tenantId, staging.MapId(), enRoute.MapId())
+
+	_, ok := r.Get(tenantId, staging)
+	assert.False(t, ok)
+
+	r.Reconcile(tenantId, staging, []uint32{1, 2}, time.Now())
+	ids, ok := r.Get(tenantId, staging)
+	assert.True(t, ok)
+	assert.ElementsMatch(t, []uint32{1, 2}, ids)
+
+	r.Reconcile(tenantId, enRoute, []uint32{}, time.Now())
+	r.Enter(tenantId, 1, enRoute)
+	r.Enter(tenantId, 2, elsewhere)
+	r.Enter(tenantId, 3, staging)
+
+	ids, _ = r.Get(tenantId, staging)
+	assert.ElementsMatch(t, []uint32{3}, ids)
+	ids, _ = r.Get(tenantId, enRoute)
+	assert.ElementsMatch(t, []uint32{1}, ids)
+
+	r.Leave(tenantId, 1)
+	ids, _ = r.Get(tenantId, enRoute)
+	assert.Empty(t, ids)
+}
@@ -0,0 +1,45 @@
+package occupancy
+
+import (
+	"github.com/Chronicle20/atlas-constants/channel"
+	"github.com/Chronicle20/atlas-constants/field"
+	_map "github.com/Chronicle20/atlas-constants/map"
+	"github.com/Chronicle20/atlas-constants/world"
+)
+
+// RestModel is the JSON:API resource for the characters in a field
+type RestModel struct {
+	Id           string     `json:"-"`
+	WorldId      world.Id   `json:"worldId"`
+	ChannelId    channel.Id `json:"channelId"`
+	MapId        _map.Id    `json:"mapId"`
+	CharacterIds []uint32   `json:"characterIds"`
+}
+
+func (r RestModel) GetName() string {
+	return "occupancies"
+}
+
+func (r RestModel) GetID() string {
+	return r.Id
+}
+
+func (r *RestModel) SetID(idStr string) error {
+	r.Id = idStr
+	return nil
+}
+
+func Transform(m Model) (RestModel, error) {
+	return RestModel{
+		Id:           string(m.Field().Id()),
+		WorldId:      m.Field().WorldId(),
+		ChannelId:    m.Field().ChannelId(),
+		MapId:        m.Field().MapId(),
+		CharacterIds: m.CharacterIds(),
+	}, nil
+}
+
+func Extract(r RestModel) (Model, error) {
+	f := field.NewBuilder(r.WorldId, r.ChannelId, r.MapId).Build()
+	return NewModel(f, r.CharacterIds), nil
+}
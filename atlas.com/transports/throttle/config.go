@@ -0,0 +1,51 @@
+package throttle
+
+import (
+	"encoding/json"
+	"os"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultConcurrency   = 8
+	defaultRatePerSecond = 50
+)
+
+func defaults() Model {
+	return Model{
+		concurrency:   envInt("WARP_CONCURRENCY", defaultConcurrency),
+		ratePerSecond: envInt("WARP_RATE_LIMIT", defaultRatePerSecond),
+	}
+}
+
+// configFor resolves the configuration of the tenant. TENANT_THROTTLES holds per-tenant overrides as a JSON object
+// keyed by tenant id, for example {"083839c6-c47c-42a6-9585-76492795d123":{"concurrency":4,"ratePerSecond":20}}. A
+// malformed value is logged, and the defaults are used.
+func configFor(l logrus.FieldLogger, tenantId uuid.UUID) Model {
+	val, ok := os.LookupEnv("TENANT_THROTTLES")
+	if !ok {
+		return defaults()
+	}
+	var overrides map[string]RestModel
+	if err := json.Unmarshal([]byte(val), &overrides); err != nil {
+		l.WithError(err).Errorf("Unable to parse TENANT_THROTTLES, using default throttle for tenant [%s].", tenantId)
+		return defaults()
+	}
+	if r, ok := overrides[tenantId.String()]; ok {
+		m, _ := Extract(r)
+		return m
+	}
+	return defaults()
+}
+
+func envInt(key string, def int) int {
+	if val, ok := os.LookupEnv(key); ok {
+		if i, err := strconv.Atoi(val); err == nil && i > 0 {
+			return i
+		}
+	}
+	return def
+}
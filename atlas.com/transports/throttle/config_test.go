@@ -0,0 +1,36 @@
+package throttle
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigFor_AppliesTenantOverride(t *testing.T) {
+	l, _ := test.NewNullLogger()
+	tenantId := uuid.New()
+	t.Setenv("WARP_CONCURRENCY", "6")
+	t.Setenv("TENANT_THROTTLES", `{"`+tenantId.String()+`":{"ratePerSecond":20}}`)
+
+	c := configFor(l, tenantId)
+	assert.Equal(t, 6, c.Concurrency())
+	assert.Equal(t, 20, c.RatePerSecond())
+
+	c = configFor(l, uuid.New())
+	assert.Equal(t, 6, c.Concurrency())
+	assert.Equal(t, defaultRatePerSecond, c.RatePerSecond())
+}
+
+func TestConfigFor_LogsMalformedOverrides(t *testing.T) {
+	l, hook := test.NewNullLogger()
+	t.Setenv("TENANT_THROTTLES", `{"not json"`)
+
+	c := configFor(l, uuid.New())
+	assert.Equal(t, defaults(), c)
+	if assert.Len(t, hook.Entries, 1) {
+		assert.Equal(t, logrus.ErrorLevel, hook.LastEntry().Level)
+	}
+}
@@ -0,0 +1,101 @@
+package throttle
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter bounds the concurrency and rate of a tenant's fan-out work.
+type Limiter struct {
+	config Model
+	sem    chan struct{}
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func NewLimiter(config Model) *Limiter {
+	return &Limiter{
+		config: config,
+		sem:    make(chan struct{}, config.Concurrency()),
+		tokens: float64(config.RatePerSecond()),
+		last:   time.Now(),
+	}
+}
+
+func (l *Limiter) Config() Model {
+	return l.config
+}
+
+// Burst returns the most operations the rate limit allows at once.
+func (l *Limiter) Burst() int {
+	return l.config.RatePerSecond()
+}
+
+// Wait blocks until the rate limit allows one more operation, or the context is done.
+func (l *Limiter) Wait(ctx context.Context) error {
+	return l.WaitN(ctx, 1)
+}
+
+// WaitN blocks until the rate limit allows n more operations, or the context is done. n is capped at the burst.
+func (l *Limiter) WaitN(ctx context.Context, n int) error {
+	n = min(n, l.Burst())
+	for {
+		d := l.reserve(n)
+		if d <= 0 {
+			return nil
+		}
+		t := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// reserve takes n tokens if they are available, otherwise returns how long until they are.
+func (l *Limiter) reserve(n int) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	rate := float64(l.config.RatePerSecond())
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * rate
+	if l.tokens > rate {
+		l.tokens = rate
+	}
+	l.last = now
+	if l.tokens >= float64(n) {
+		l.tokens -= float64(n)
+		return 0
+	}
+	return time.Duration((float64(n) - l.tokens) / rate * float64(time.Second))
+}
+
+// ForEach runs the operator over every item, with at most the configured number of operators running at once. All
+// items are attempted; the first error encountered is returned.
+func ForEach[T any](l *Limiter, items []T, operator func(T) error) error {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var first error
+	for _, item := range items {
+		l.sem <- struct{}{}
+		wg.Add(1)
+		go func(item T) {
+			defer wg.Done()
+			defer func() { <-l.sem }()
+			if err := operator(item); err != nil {
+				mu.Lock()
+				if first == nil {
+					first = err
+				}
+				mu.Unlock()
+			}
+		}(item)
+	}
+	wg.Wait()
+	return first
+}
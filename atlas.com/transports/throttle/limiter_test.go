@@ -0,0 +1,63 @@
+package throttle
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter_WaitNPacesToRate(t *testing.T) {
+	l := NewLimiter(Model{concurrency: 1, ratePerSecond: 100})
+
+	start := time.Now()
+	assert.NoError(t, l.WaitN(context.Background(), 100))
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+
+	assert.NoError(t, l.WaitN(context.Background(), 20))
+	assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+}
+
+func TestLimiter_WaitNCapsAtBurst(t *testing.T) {
+	l := NewLimiter(Model{concurrency: 1, ratePerSecond: 10})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.NoError(t, l.WaitN(ctx, 1000))
+}
+
+func TestLimiter_WaitNStopsWithContext(t *testing.T) {
+	l := NewLimiter(Model{concurrency: 1, ratePerSecond: 1})
+	assert.NoError(t, l.Wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, l.Wait(ctx), context.DeadlineExceeded)
+}
+
+func TestForEach_BoundsConcurrency(t *testing.T) {
+	l := NewLimiter(Model{concurrency: 3, ratePerSecond: 100})
+
+	var mu sync.Mutex
+	var running, peak int
+	var done atomic.Int32
+	items := make([]int, 20)
+	err := ForEach(l, items, func(int) error {
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		done.Add(1)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(20), done.Load())
+	assert.LessOrEqual(t, peak, 3)
+}
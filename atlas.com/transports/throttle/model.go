@@ -0,0 +1,35 @@
+package throttle
+
+// Model is the fan-out configuration of a tenant
+type Model struct {
+	concurrency   int
+	ratePerSecond int
+}
+
+// Concurrency returns the maximum number of tasks a tenant runs at once
+func (m Model) Concurrency() int {
+	return m.concurrency
+}
+
+// RatePerSecond returns the maximum number of rate-limited operations a tenant performs per second
+func (m Model) RatePerSecond() int {
+	return m.ratePerSecond
+}
+
+// RestModel is the JSON shape of a tenant's fan-out configuration
+type RestModel struct {
+	Concurrency   int `json:"concurrency"`
+	RatePerSecond int `json:"ratePerSecond"`
+}
+
+// Extract converts a RestModel to a Model, using the defaults for unset values
+func Extract(r RestModel) (Model, error) {
+	m := defaults()
+	if r.Concurrency > 0 {
+		m.concurrency = r.Concurrency
+	}
+	if r.RatePerSecond > 0 {
+		m.ratePerSecond = r.RatePerSecond
+	}
+	return m, nil
+}
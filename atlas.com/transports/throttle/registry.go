@@ -0,0 +1,43 @@
+package throttle
+
+import (
+	"sync"
+
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type Registry struct {
+	mu       sync.Mutex
+	limiters map[uuid.UUID]*Limiter
+}
+
+var registry *Registry
+var registryOnce sync.Once
+
+func getRegistry() *Registry {
+	registryOnce.Do(func() {
+		registry = &Registry{
+			limiters: make(map[uuid.UUID]*Limiter),
+		}
+	})
+	return registry
+}
+
+// Get returns the limiter of the tenant, creating it from the tenant's configuration on first use.
+func (r *Registry) Get(l logrus.FieldLogger, tenantId uuid.UUID) *Limiter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if lim, ok := r.limiters[tenantId]; ok {
+		return lim
+	}
+	lim := NewLimiter(configFor(l, tenantId))
+	r.limiters[tenantId] = lim
+	return lim
+}
+
+// ForTenant returns the limiter shared by all fan-out work of the tenant.
+func ForTenant(l logrus.FieldLogger, t tenant.Model) *Limiter {
+	return getRegistry().Get(l, t.Id())
+}
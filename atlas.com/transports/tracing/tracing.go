@@ -1,35 +1,58 @@
 package tracing
 
 import (
-	"fmt"
+	"context"
 	"github.com/opentracing/opentracing-go"
 	"github.com/sirupsen/logrus"
-	"github.com/uber/jaeger-client-go/config"
-	"io"
-	"os"
+	"go.opentelemetry.io/otel"
+	otbridge "go.opentelemetry.io/otel/bridge/opentracing"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"time"
 )
 
-func InitTracer(l logrus.FieldLogger) func(serviceName string) (io.Closer, error) {
-	return func(serviceName string) (io.Closer, error) {
-		jaegerHostPort := os.Getenv("JAEGER_HOST_PORT")
-		cfg := &config.Configuration{
-			ServiceName: serviceName,
-			Sampler:     &config.SamplerConfig{Type: "const", Param: 1},
-			Reporter:    &config.ReporterConfig{LogSpans: true, LocalAgentHostPort: jaegerHostPort},
+const instrumentationName = "atlas-transports"
+
+// InitTracer installs an OpenTelemetry tracer provider which exports spans over OTLP. The exporter is configured
+// through the standard OTEL_EXPORTER_OTLP_* environment variables. Libraries still instrumented with OpenTracing are
+// bridged onto the same provider, so their spans join the same traces.
+func InitTracer(l logrus.FieldLogger) func(serviceName string) (*sdktrace.TracerProvider, error) {
+	return func(serviceName string) (*sdktrace.TracerProvider, error) {
+		exporter, err := otlptracehttp.New(context.Background())
+		if err != nil {
+			return nil, err
 		}
-		tracer, closer, err := cfg.NewTracer(config.Logger(LogrusAdapter{logger: l}))
+		res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName)))
 		if err != nil {
 			return nil, err
 		}
-		opentracing.SetGlobalTracer(tracer)
-		return closer, nil
+		tp := sdktrace.NewTracerProvider(
+			sdktrace.WithBatcher(exporter),
+			sdktrace.WithResource(res),
+		)
+
+		bridge, provider := otbridge.NewTracerPair(tp.Tracer(instrumentationName))
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+		opentracing.SetGlobalTracer(bridge)
+		otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+			l.WithError(err).Warn("Tracing error.")
+		}))
+		return tp, nil
 	}
 }
 
-func Teardown(l logrus.FieldLogger) func(tc io.Closer) func() {
-	return func(tc io.Closer) func() {
+func Teardown(l logrus.FieldLogger) func(tp *sdktrace.TracerProvider) func() {
+	return func(tp *sdktrace.TracerProvider) func() {
 		return func() {
-			err := tc.Close()
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			err := tp.Shutdown(ctx)
 			if err != nil {
 				l.WithError(err).Errorf("Unable to close tracer.")
 			}
@@ -37,20 +60,19 @@ func Teardown(l logrus.FieldLogger) func(tc io.Closer) func() {
 	}
 }
 
-type LogrusAdapter struct {
-	logger logrus.FieldLogger
+// StartSpan starts a span as a child of any span in the context. The returned logger carries the trace and span ids.
+func StartSpan(l logrus.FieldLogger, ctx context.Context, name string, opts ...trace.SpanStartOption) (logrus.FieldLogger, context.Context, trace.Span) {
+	ctx, span := otel.Tracer(instrumentationName).Start(ctx, name, opts...)
+	sc := span.SpanContext()
+	sl := l.WithField("trace.id", sc.TraceID().String()).WithField("span.id", sc.SpanID().String())
+	return sl, ctx, span
 }
 
-func (l LogrusAdapter) Error(msg string) {
-	l.logger.Error(msg)
-}
-
-func (l LogrusAdapter) Infof(msg string, args ...interface{}) {
-	l.logger.Infof(msg, args)
-}
-
-func StartSpan(l logrus.FieldLogger, name string, opts ...opentracing.StartSpanOption) (logrus.FieldLogger, opentracing.Span) {
-	span := opentracing.StartSpan(name, opts...)
-	sl := l.WithField("span.id", fmt.Sprintf("%v", span))
-	return sl, span
+// EndSpan records the error, if any, on the span and ends it.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
 }
@@ -9,17 +9,22 @@ import (
 
 // RouteRestModel is the JSON:API resource for routes
 type RouteRestModel struct {
-	Id                     uuid.UUID     `json:"-"`
-	Name                   string        `json:"name"`
-	StartMapId             _map.Id       `json:"startMapId"`
-	StagingMapId           _map.Id       `json:"stagingMapId"`
-	EnRouteMapIds          []_map.Id     `json:"enRouteMapIds"`
-	DestinationMapId       _map.Id       `json:"destinationMapId"`
-	ObservationMapId       _map.Id       `json:"observationMapId"`
-	BoardingWindowDuration time.Duration `json:"boardingWindowDuration"`
-	PreDepartureDuration   time.Duration `json:"preDepartureDuration"`
-	TravelDuration         time.Duration `json:"travelDuration"`
-	CycleInterval          time.Duration `json:"cycleInterval"`
+	Id                     uuid.UUID                            `json:"-"`
+	Name                   string                               `json:"name"`
+	StartMapId             _map.Id                              `json:"startMapId"`
+	StagingMapId           _map.Id                              `json:"stagingMapId"`
+	EnRouteMapIds          []_map.Id                            `json:"enRouteMapIds"`
+	DestinationMapId       _map.Id                              `json:"destinationMapId"`
+	ObservationMapId       _map.Id                              `json:"observationMapId"`
+	BoardingWindowDuration time.Duration                        `json:"boardingWindowDuration"`
+	PreDepartureDuration   time.Duration                        `json:"preDepartureDuration"`
+	TravelDuration         time.Duration                        `json:"travelDuration"`
+	CycleInterval          time.Duration                        `json:"cycleInterval"`
+	DepartureWarp          transport.WarpStrategyRestModel      `json:"departureWarp"`
+	ArrivalWarp            transport.WarpStrategyRestModel      `json:"arrivalWarp"`
+	LogoutPolicies         map[string]string                    `json:"logoutPolicies"`
+	Enablement             []transport.WorldEnablementRestModel `json:"enablement"`
+	ChannelStaggerMs       uint32                               `json:"channelStaggerMs"`
 }
 
 // GetID returns the resource ID
@@ -53,7 +58,12 @@ func ExtractRoute(r RouteRestModel) (transport.Model, error) {
 		SetBoardingWindowDuration(r.BoardingWindowDuration * time.Minute).
 		SetPreDepartureDuration(r.PreDepartureDuration * time.Minute).
 		SetTravelDuration(r.TravelDuration * time.Minute).
-		SetCycleInterval(r.CycleInterval * time.Minute)
+		SetCycleInterval(r.CycleInterval * time.Minute).
+		SetDepartureWarp(transport.ExtractWarpStrategy(r.DepartureWarp)).
+		SetArrivalWarp(transport.ExtractWarpStrategy(r.ArrivalWarp)).
+		SetLogoutPolicies(transport.ExtractLogoutPolicies(r.LogoutPolicies)).
+		SetEnablement(transport.ExtractEnablement(r.Enablement)).
+		SetChannelStagger(time.Duration(r.ChannelStaggerMs) * time.Millisecond)
 
 	for _, mapId := range r.EnRouteMapIds {
 		builder.AddEnRouteMapId(mapId)
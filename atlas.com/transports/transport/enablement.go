@@ -0,0 +1,44 @@
+package transport
+
+import (
+	"github.com/Chronicle20/atlas-constants/channel"
+	"github.com/Chronicle20/atlas-constants/world"
+)
+
+// WorldEnablementModel enables a route in a world. When no channels are listed, the route runs on every channel of the
+// world.
+type WorldEnablementModel struct {
+	worldId    world.Id
+	channelIds []channel.Id
+}
+
+func NewWorldEnablementModel(worldId world.Id, channelIds []channel.Id) WorldEnablementModel {
+	return WorldEnablementModel{
+		worldId:    worldId,
+		channelIds: channelIds,
+	}
+}
+
+func (m WorldEnablementModel) WorldId() world.Id {
+	return m.worldId
+}
+
+func (m WorldEnablementModel) ChannelIds() []channel.Id {
+	return m.channelIds
+}
+
+// Includes reports whether the channel of the world is enabled.
+func (m WorldEnablementModel) Includes(worldId world.Id, channelId channel.Id) bool {
+	if m.worldId != worldId {
+		return false
+	}
+	if len(m.channelIds) == 0 {
+		return true
+	}
+	for _, id := range m.channelIds {
+		if id == channelId {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package transport
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Chronicle20/atlas-constants/channel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModel_EnabledIn(t *testing.T) {
+	everywhere := NewBuilder("Everywhere").Build()
+	assert.True(t, everywhere.EnabledIn(0, 1))
+	assert.True(t, everywhere.EnabledIn(5, 19))
+
+	restricted := NewBuilder("Restricted").
+		SetEnablement([]WorldEnablementModel{
+			NewWorldEnablementModel(0, nil),
+			NewWorldEnablementModel(1, []channel.Id{2, 3}),
+		}).
+		Build()
+	assert.True(t, restricted.EnabledIn(0, 7))
+	assert.True(t, restricted.EnabledIn(1, 3))
+	assert.False(t, restricted.EnabledIn(1, 4))
+	assert.False(t, restricted.EnabledIn(2, 2))
+}
+
+func TestModel_ChannelOffset(t *testing.T) {
+	route := NewBuilder("Staggered").SetChannelStagger(250 * time.Millisecond).Build()
+	assert.Equal(t, time.Duration(0), route.ChannelOffset(0))
+	assert.Equal(t, 1*time.Second, route.ChannelOffset(4))
+	assert.Equal(t, time.Duration(0), NewBuilder("Unstaggered").Build().ChannelOffset(4))
+}
@@ -0,0 +1,149 @@
+package transport
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	icalTimeFormat = "20060102T150405Z"
+	icalLineLimit  = 75
+)
+
+// scheduledTrip is a dated trip together with the route it runs on.
+type scheduledTrip struct {
+	route Model
+	trip  TripScheduleModel
+}
+
+// scheduledTrips flattens the schedules of the routes, ordered by the time boarding opens.
+func scheduledTrips(routes []Model) []scheduledTrip {
+	results := make([]scheduledTrip, 0)
+	for _, r := range routes {
+		for _, trip := range r.Schedule() {
+			results = append(results, scheduledTrip{route: r, trip: trip})
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].trip.BoardingOpen().Before(results[j].trip.BoardingOpen())
+	})
+	return results
+}
+
+// scheduleDateFormat is the layout of a schedule window bound given as a date.
+const scheduleDateFormat = "2006-01-02"
+
+// parseScheduleBound parses a bound of a schedule window, given as an RFC 3339 timestamp or as a date standing for the
+// start of that day in loc. An empty value is a zero time.
+func parseScheduleBound(value string, loc *time.Location) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation(scheduleDateFormat, value, loc); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// scheduleWindow fills in the bounds of a schedule window left unset. Days are resolved in loc, so the window defaults
+// to the day of now in the tenant's zone, and ends a day after it starts, however long that day is.
+func scheduleWindow(from time.Time, to time.Time, loc *time.Location, now time.Time) (time.Time, time.Time) {
+	if from.IsZero() {
+		n := now.In(loc)
+		from = time.Date(n.Year(), n.Month(), n.Day(), 0, 0, 0, 0, loc)
+	}
+	if to.IsZero() {
+		to = from.In(loc).AddDate(0, 0, 1)
+	}
+	return from, to
+}
+
+// WriteICalendar writes the dated schedules of the routes as an iCalendar feed, with an event per trip running from
+// boarding open to arrival. Times are written in UTC, and loc is given as the zone calendars should display them in.
+func WriteICalendar(w io.Writer, name string, loc *time.Location, stamp time.Time) func(routes []Model) error {
+	return func(routes []Model) error {
+		bw := bufio.NewWriter(w)
+		line := func(l string) {
+			writeICalLine(bw, l)
+		}
+		line("BEGIN:VCALENDAR")
+		line("VERSION:2.0")
+		line("PRODID:-//Chronicle20//atlas-transports//EN")
+		line("CALSCALE:GREGORIAN")
+		line("METHOD:PUBLISH")
+		line("X-WR-CALNAME:" + escapeICalText(name))
+		line("X-WR-TIMEZONE:" + loc.String())
+		for _, st := range scheduledTrips(routes) {
+			r, trip := st.route, st.trip
+			line("BEGIN:VEVENT")
+			line(fmt.Sprintf("UID:%s@atlas-transports", TripOccurrenceId(trip.TripId(), trip.Departure())))
+			line("DTSTAMP:" + stamp.UTC().Format(icalTimeFormat))
+			line("DTSTART:" + trip.BoardingOpen().UTC().Format(icalTimeFormat))
+			line("DTEND:" + trip.Arrival().UTC().Format(icalTimeFormat))
+			line("SUMMARY:" + escapeICalText(r.Name()))
+			line("LOCATION:" + escapeICalText(fmt.Sprintf("Map %d", r.StartMapId())))
+			line("DESCRIPTION:" + escapeICalText(fmt.Sprintf("From map %d to map %d.\nBoarding closes at %s, departing at %s and arriving at %s.",
+				r.StartMapId(), r.DestinationMapId(),
+				trip.BoardingClosed().In(loc).Format("15:04"), trip.Departure().In(loc).Format("15:04"), trip.Arrival().In(loc).Format("15:04"))))
+			line("END:VEVENT")
+		}
+		line("END:VCALENDAR")
+		return bw.Flush()
+	}
+}
+
+// writeICalLine writes a content line terminated by CRLF, folding it so no line exceeds 75 octets.
+func writeICalLine(w *bufio.Writer, l string) {
+	limit := icalLineLimit
+	for len(l) > limit {
+		cut := limit
+		// Never split a multi-byte character.
+		for cut > 0 && l[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		_, _ = w.WriteString(l[:cut] + "\r\n ")
+		l = l[cut:]
+		// Continuation lines begin with a space, which counts towards their length.
+		limit = icalLineLimit - 1
+	}
+	_, _ = w.WriteString(l + "\r\n")
+}
+
+func escapeICalText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// WriteCSV writes the dated schedules of the routes as CSV, with a row per trip and times in loc.
+func WriteCSV(w io.Writer, loc *time.Location) func(routes []Model) error {
+	return func(routes []Model) error {
+		cw := csv.NewWriter(w)
+		err := cw.Write([]string{"tripId", "routeId", "routeName", "startMapId", "destinationMapId", "boardingOpen", "boardingClosed", "departure", "arrival"})
+		if err != nil {
+			return err
+		}
+		for _, st := range scheduledTrips(routes) {
+			r, trip := st.route, st.trip
+			err = cw.Write([]string{
+				trip.TripId().String(),
+				r.Id().String(),
+				r.Name(),
+				fmt.Sprintf("%d", r.StartMapId()),
+				fmt.Sprintf("%d", r.DestinationMapId()),
+				trip.BoardingOpen().In(loc).Format(time.RFC3339),
+				trip.BoardingClosed().In(loc).Format(time.RFC3339),
+				trip.Departure().In(loc).Format(time.RFC3339),
+				trip.Arrival().In(loc).Format(time.RFC3339),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+}
@@ -0,0 +1,112 @@
+package transport
+
+import (
+	"atlas-transports/clock"
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	_map "github.com/Chronicle20/atlas-constants/map"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func exportRoute() Model {
+	route := NewBuilder("Ellinia to Orbis, the long way").
+		SetId(uuid.MustParse("11111111-1111-1111-1111-111111111111")).
+		SetStartMapId(101000300).
+		SetStagingMapId(101000301).
+		SetEnRouteMapIds([]_map.Id{200090010}).
+		SetDestinationMapId(200000100).
+		SetBoardingWindowDuration(4 * time.Minute).
+		SetPreDepartureDuration(1 * time.Minute).
+		SetTravelDuration(10 * time.Minute).
+		SetCycleInterval(6 * time.Hour).
+		Build()
+	return ScheduleRoutes(clock.Fixed(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)), []Model{route}, nil)[0]
+}
+
+func TestModel_TripsBetween(t *testing.T) {
+	from := time.Date(2025, 3, 1, 20, 0, 0, 0, time.UTC)
+	trips := exportRoute().TripsBetween(from, from.Add(24*time.Hour))
+
+	assert.Len(t, trips, 4)
+	assert.Equal(t, time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC), trips[0].BoardingOpen())
+	assert.Equal(t, time.Date(2025, 3, 2, 0, 15, 0, 0, time.UTC), trips[0].Arrival())
+	assert.Equal(t, time.Date(2025, 3, 2, 18, 0, 0, 0, time.UTC), trips[3].BoardingOpen())
+}
+
+func TestWriteICalendar(t *testing.T) {
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	route := exportRoute()
+	route = route.Builder().SetSchedule(route.TripsBetween(from, from.Add(12*time.Hour))).Build()
+	loc, _ := time.LoadLocation("Asia/Seoul")
+
+	var b bytes.Buffer
+	assert.NoError(t, WriteICalendar(&b, route.Name(), loc, from)([]Model{route}))
+	out := b.String()
+
+	assert.Equal(t, 2, strings.Count(out, "BEGIN:VEVENT"))
+	assert.Contains(t, out, "X-WR-TIMEZONE:Asia/Seoul\r\n")
+	assert.Contains(t, out, "DTSTART:20250301T060000Z\r\nDTEND:20250301T061500Z\r\n")
+	assert.Contains(t, out, `SUMMARY:Ellinia to Orbis\, the long way`)
+	for _, l := range strings.Split(out, "\r\n") {
+		assert.LessOrEqual(t, len(l), icalLineLimit)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	route := exportRoute()
+	route = route.Builder().SetSchedule(route.TripsBetween(from, from.Add(24*time.Hour))).Build()
+	loc, _ := time.LoadLocation("Asia/Seoul")
+
+	var b bytes.Buffer
+	assert.NoError(t, WriteCSV(&b, loc)([]Model{route}))
+	rows, err := csv.NewReader(&b).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, rows, 5)
+	assert.Equal(t, "Ellinia to Orbis, the long way", rows[1][2])
+	assert.Equal(t, "2025-03-01T09:00:00+09:00", rows[1][5])
+}
+
+func TestScheduleWindow_ResolvesDaysInTenantZone(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("zone database unavailable")
+	}
+	now := time.Date(2025, 3, 1, 23, 30, 0, 0, time.UTC)
+
+	from, to := scheduleWindow(time.Time{}, time.Time{}, berlin, now)
+	assert.True(t, time.Date(2025, 3, 2, 0, 0, 0, 0, berlin).Equal(from))
+	assert.True(t, time.Date(2025, 3, 3, 0, 0, 0, 0, berlin).Equal(to))
+
+	// The day clocks change lasts 23 hours
+	from, err = parseScheduleBound("2025-03-30", berlin)
+	assert.NoError(t, err)
+	from, to = scheduleWindow(from, time.Time{}, berlin, now)
+	assert.Equal(t, 23*time.Hour, to.Sub(from))
+
+	trips := exportRoute().TripsBetween(from, to)
+	assert.Len(t, trips, 4)
+	assert.Equal(t, time.Date(2025, 3, 30, 0, 0, 0, 0, time.UTC), trips[0].BoardingOpen())
+}
+
+func TestTimezoneFor_LogsMalformedConfiguration(t *testing.T) {
+	l, hook := test.NewNullLogger()
+	t.Setenv("TENANT_TIMEZONES", "Europe/Berlin")
+	assert.Equal(t, time.Local, timezoneFor(l, uuid.New()))
+	if assert.Len(t, hook.Entries, 1) {
+		assert.Equal(t, logrus.ErrorLevel, hook.LastEntry().Level)
+	}
+
+	hook.Reset()
+	tenantId := uuid.New()
+	t.Setenv("TENANT_TIMEZONES", `{"`+tenantId.String()+`":"Mars/Olympus_Mons"}`)
+	assert.Equal(t, time.Local, timezoneFor(l, tenantId))
+	assert.Len(t, hook.Entries, 1)
+}
@@ -0,0 +1,90 @@
+package transport
+
+import (
+	"sync"
+	"time"
+
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/google/uuid"
+)
+
+// transitionHistoryLimit is the number of transitions retained per route. Older records are discarded first.
+const transitionHistoryLimit = 200
+
+type TransitionHistoryRegistry struct {
+	mutex   sync.RWMutex
+	records map[uuid.UUID]map[uuid.UUID][]TransitionRecordModel
+}
+
+var transitionHistoryRegistry *TransitionHistoryRegistry
+var transitionHistoryRegistryOnce sync.Once
+
+func getTransitionHistoryRegistry() *TransitionHistoryRegistry {
+	transitionHistoryRegistryOnce.Do(func() {
+		transitionHistoryRegistry = &TransitionHistoryRegistry{}
+		transitionHistoryRegistry.records = make(map[uuid.UUID]map[uuid.UUID][]TransitionRecordModel)
+	})
+	return transitionHistoryRegistry
+}
+
+// Add records a transition attempt of a route, discarding the oldest record once the route's history is full. A failed
+// attempt which repeats the most recent failed attempt of the same transition replaces it, counting the attempts, so
+// that retries do not evict other transitions from the history. The stored record is returned.
+func (r *TransitionHistoryRegistry) Add(t tenant.Model, m TransitionRecordModel) TransitionRecordModel {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, ok := r.records[t.Id()]; !ok {
+		r.records[t.Id()] = make(map[uuid.UUID][]TransitionRecordModel)
+	}
+	if m.attempts == 0 {
+		m.attempts = 1
+	}
+	rs := r.records[t.Id()][m.RouteId()]
+	if n := len(rs); n > 0 && !m.Succeeded() && retries(rs[n-1], m) {
+		m.id = rs[n-1].id
+		m.attempts += rs[n-1].attempts
+		rs[n-1] = m
+		return m
+	}
+	rs = append(rs, m)
+	if len(rs) > transitionHistoryLimit {
+		rs = append([]TransitionRecordModel(nil), rs[len(rs)-transitionHistoryLimit:]...)
+	}
+	r.records[t.Id()][m.RouteId()] = rs
+	return m
+}
+
+// retries reports whether the attempt repeats a failed attempt of the same scheduled transition.
+func retries(last TransitionRecordModel, m TransitionRecordModel) bool {
+	return !last.Succeeded() && last.fromState == m.fromState && last.toState == m.toState && last.scheduledAt.Equal(m.scheduledAt)
+}
+
+// AddPassengersMoved counts warps published after the transition was recorded, such as those of staggered channels.
+func (r *TransitionHistoryRegistry) AddPassengersMoved(t tenant.Model, routeId uuid.UUID, id uuid.UUID, moved int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	rs := r.records[t.Id()][routeId]
+	for i := range rs {
+		if rs[i].id == id {
+			rs[i].passengersMoved += moved
+			return
+		}
+	}
+}
+
+// Get returns the transitions of a route which occurred within [since, until), oldest first. A zero bound is open.
+func (r *TransitionHistoryRegistry) Get(t tenant.Model, routeId uuid.UUID, since time.Time, until time.Time) []TransitionRecordModel {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	results := make([]TransitionRecordModel, 0)
+	for _, m := range r.records[t.Id()][routeId] {
+		if !since.IsZero() && m.OccurredAt().Before(since) {
+			continue
+		}
+		if !until.IsZero() && !m.OccurredAt().Before(until) {
+			continue
+		}
+		results = append(results, m)
+	}
+	return results
+}
@@ -0,0 +1,69 @@
+package transport
+
+import (
+	"testing"
+	"time"
+
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransitionHistoryRegistry_Bounded(t *testing.T) {
+	ten, _ := tenant.Register(uuid.New(), "GMS", 83, 1)
+	routeId := uuid.New()
+	now := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	r := getTransitionHistoryRegistry()
+
+	for i := 0; i < transitionHistoryLimit+5; i++ {
+		r.Add(ten, TransitionRecordModel{id: uuid.New(), routeId: routeId, occurredAt: now.Add(time.Duration(i) * time.Minute)})
+	}
+	rs := r.Get(ten, routeId, time.Time{}, time.Time{})
+	assert.Len(t, rs, transitionHistoryLimit)
+	assert.Equal(t, now.Add(5*time.Minute), rs[0].OccurredAt())
+}
+
+func TestTransitionHistoryRegistry_Filters(t *testing.T) {
+	ten, _ := tenant.Register(uuid.New(), "GMS", 83, 1)
+	routeId := uuid.New()
+	now := time.Date(2023, 1, 1, 14, 30, 0, 0, time.UTC)
+	r := getTransitionHistoryRegistry()
+
+	r.Add(ten, TransitionRecordModel{id: uuid.New(), routeId: routeId, fromState: OpenEntry, toState: LockedEntry, occurredAt: now})
+	r.Add(ten, TransitionRecordModel{id: uuid.New(), routeId: routeId, fromState: LockedEntry, toState: InTransit, occurredAt: now.Add(2 * time.Minute), err: "unable to reach broker"})
+	r.Add(ten, TransitionRecordModel{id: uuid.New(), routeId: routeId, fromState: InTransit, toState: AwaitingReturn, occurredAt: now.Add(10 * time.Minute)})
+
+	rs := r.Get(ten, routeId, now.Add(time.Minute), now.Add(10*time.Minute))
+	assert.Len(t, rs, 1)
+	assert.Equal(t, InTransit, rs[0].ToState())
+	assert.False(t, rs[0].Succeeded())
+	assert.Empty(t, r.Get(ten, uuid.New(), time.Time{}, time.Time{}))
+}
+
+func TestTransitionHistoryRegistry_CollapsesRepeatedFailures(t *testing.T) {
+	ten, _ := tenant.Register(uuid.New(), "GMS", 83, 1)
+	routeId := uuid.New()
+	now := time.Date(2023, 1, 1, 12, 7, 0, 0, time.UTC)
+	r := getTransitionHistoryRegistry()
+
+	r.Add(ten, TransitionRecordModel{id: uuid.New(), routeId: routeId, fromState: OpenEntry, toState: LockedEntry, scheduledAt: now.Add(-2 * time.Minute), occurredAt: now.Add(-2 * time.Minute)})
+	first := r.Add(ten, TransitionRecordModel{id: uuid.New(), routeId: routeId, fromState: LockedEntry, toState: InTransit, scheduledAt: now, occurredAt: now, err: "unable to reach broker"})
+	for i := 1; i <= transitionHistoryLimit; i++ {
+		r.Add(ten, TransitionRecordModel{id: uuid.New(), routeId: routeId, fromState: LockedEntry, toState: InTransit, scheduledAt: now, occurredAt: now.Add(time.Duration(i) * time.Second), err: "unable to reach broker"})
+	}
+	r.Add(ten, TransitionRecordModel{id: uuid.New(), routeId: routeId, fromState: LockedEntry, toState: InTransit, scheduledAt: now, occurredAt: now.Add(5 * time.Minute), passengersMoved: 4})
+
+	rs := r.Get(ten, routeId, time.Time{}, time.Time{})
+	if assert.Len(t, rs, 3) {
+		assert.Equal(t, LockedEntry, rs[0].ToState())
+		assert.Equal(t, first.Id(), rs[1].Id())
+		assert.Equal(t, transitionHistoryLimit+1, rs[1].Attempts())
+		assert.False(t, rs[1].Succeeded())
+		assert.True(t, rs[2].Succeeded())
+		assert.Equal(t, 1, rs[2].Attempts())
+		assert.Equal(t, 4, rs[2].PassengersMoved())
+
+		r.AddPassengersMoved(ten, routeId, rs[2].Id(), 3)
+		assert.Equal(t, 7, r.Get(ten, routeId, time.Time{}, time.Time{})[2].PassengersMoved())
+	}
+}
@@ -0,0 +1,49 @@
+package transport
+
+import (
+	"sync"
+
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/google/uuid"
+)
+
+// InFlightRegistry tracks the routes whose update is running, so that a tick does not start another update of a route
+// still publishing the side effects of the last one.
+type InFlightRegistry struct {
+	mutex    sync.Mutex
+	inFlight map[uuid.UUID]map[uuid.UUID]bool
+}
+
+var inFlightRegistry *InFlightRegistry
+var inFlightRegistryOnce sync.Once
+
+func getInFlightRegistry() *InFlightRegistry {
+	inFlightRegistryOnce.Do(func() {
+		inFlightRegistry = &InFlightRegistry{}
+		inFlightRegistry.inFlight = make(map[uuid.UUID]map[uuid.UUID]bool)
+	})
+	return inFlightRegistry
+}
+
+// Acquire marks the route's update as running, and reports false if it already was.
+func (r *InFlightRegistry) Acquire(t tenant.Model, routeId uuid.UUID) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, ok := r.inFlight[t.Id()]; !ok {
+		r.inFlight[t.Id()] = make(map[uuid.UUID]bool)
+	}
+	if r.inFlight[t.Id()][routeId] {
+		return false
+	}
+	r.inFlight[t.Id()][routeId] = true
+	return true
+}
+
+// Release marks the route's update as finished.
+func (r *InFlightRegistry) Release(t tenant.Model, routeId uuid.UUID) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if tenantInFlight, ok := r.inFlight[t.Id()]; ok {
+		delete(tenantInFlight, routeId)
+	}
+}
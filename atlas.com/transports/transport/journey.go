@@ -0,0 +1,205 @@
+package transport
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	_map "github.com/Chronicle20/atlas-constants/map"
+	"github.com/google/uuid"
+)
+
+const (
+	// maxJourneyLegs bounds the number of routes a journey may be made of.
+	maxJourneyLegs = 4
+	// journeyHorizon is how far past the time a traveller is ready to depart, or to connect, trips are looked for.
+	journeyHorizon = 24 * time.Hour
+)
+
+// JourneyLegModel is a single trip taken as part of a journey.
+type JourneyLegModel struct {
+	route      Model
+	trip       TripScheduleModel
+	boardAt    time.Time
+	connection time.Duration
+}
+
+// RouteId returns the ID of the route the leg travels on
+func (m JourneyLegModel) RouteId() uuid.UUID {
+	return m.route.Id()
+}
+
+// RouteName returns the name of the route the leg travels on
+func (m JourneyLegModel) RouteName() string {
+	return m.route.Name()
+}
+
+// FromMapId returns the map the leg boards from
+func (m JourneyLegModel) FromMapId() _map.Id {
+	return m.route.StartMapId()
+}
+
+// ToMapId returns the map the leg arrives at
+func (m JourneyLegModel) ToMapId() _map.Id {
+	return m.route.DestinationMapId()
+}
+
+// Trip returns the dated trip taken
+func (m JourneyLegModel) Trip() TripScheduleModel {
+	return m.trip
+}
+
+// TripOccurrenceId returns the ID of the trip occurrence taken
+func (m JourneyLegModel) TripOccurrenceId() string {
+	return TripOccurrenceId(m.trip.TripId(), m.trip.Departure())
+}
+
+// BoardAt returns the earliest time the traveller can board, which is when boarding opens unless they arrive later
+func (m JourneyLegModel) BoardAt() time.Time {
+	return m.boardAt
+}
+
+// Connection returns how long the traveller waits to board, after the previous leg arrives or, for the first leg,
+// after they are ready to depart
+func (m JourneyLegModel) Connection() time.Duration {
+	return m.connection
+}
+
+// JourneyModel is an itinerary of trips from one map to another.
+type JourneyModel struct {
+	legs []JourneyLegModel
+}
+
+// Id returns an ID for the journey derived from the trip occurrences it is made of
+func (m JourneyModel) Id() string {
+	ids := make([]string, 0, len(m.legs))
+	for _, l := range m.legs {
+		ids = append(ids, l.TripOccurrenceId())
+	}
+	return strings.Join(ids, "+")
+}
+
+// Legs returns the trips of the journey, in the order they are taken
+func (m JourneyModel) Legs() []JourneyLegModel {
+	return m.legs
+}
+
+// BoardAt returns when the traveller boards the first leg
+func (m JourneyModel) BoardAt() time.Time {
+	return m.legs[0].BoardAt()
+}
+
+// Arrival returns when the last leg arrives
+func (m JourneyModel) Arrival() time.Time {
+	return m.legs[len(m.legs)-1].Trip().Arrival()
+}
+
+// Duration returns the time from boarding the first leg to the arrival of the last
+func (m JourneyModel) Duration() time.Duration {
+	return m.Arrival().Sub(m.BoardAt())
+}
+
+// PlanJourneys finds itineraries from one map to another for a traveller ready to depart at departAfter, treating each
+// route as a connection from its start map to its destination map which is only available during its trips. Trips
+// are caught when boarding has not closed by the time the traveller is ready. Itineraries are ordered by earliest
+// arrival, leaving out any which board earlier than another yet arrive no sooner. At most limit are returned.
+func PlanJourneys(routes []Model, fromMapId _map.Id, toMapId _map.Id, departAfter time.Time, limit int) []JourneyModel {
+	if fromMapId == toMapId || limit <= 0 {
+		return make([]JourneyModel, 0)
+	}
+
+	candidates := make([]JourneyModel, 0)
+	for _, path := range routePaths(routes, fromMapId, toMapId) {
+		for _, first := range catchableTrips(path[0], departAfter) {
+			legs := []JourneyLegModel{newJourneyLeg(path[0], first, departAfter)}
+			for _, r := range path[1:] {
+				ready := legs[len(legs)-1].Trip().Arrival()
+				trips := catchableTrips(r, ready)
+				if len(trips) == 0 {
+					legs = nil
+					break
+				}
+				legs = append(legs, newJourneyLeg(r, trips[0], ready))
+			}
+			if legs != nil {
+				candidates = append(candidates, JourneyModel{legs: legs})
+			}
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if !candidates[i].Arrival().Equal(candidates[j].Arrival()) {
+			return candidates[i].Arrival().Before(candidates[j].Arrival())
+		}
+		if !candidates[i].BoardAt().Equal(candidates[j].BoardAt()) {
+			return candidates[i].BoardAt().After(candidates[j].BoardAt())
+		}
+		return len(candidates[i].Legs()) < len(candidates[j].Legs())
+	})
+	results := make([]JourneyModel, 0)
+	var latestBoarding time.Time
+	for _, c := range candidates {
+		if len(results) > 0 && !c.BoardAt().After(latestBoarding) {
+			continue
+		}
+		results = append(results, c)
+		latestBoarding = c.BoardAt()
+		if len(results) == limit {
+			break
+		}
+	}
+	return results
+}
+
+// routePaths returns every sequence of routes from one map to another which visits no map twice.
+func routePaths(routes []Model, fromMapId _map.Id, toMapId _map.Id) [][]Model {
+	byStartMap := make(map[_map.Id][]Model)
+	for _, r := range routes {
+		byStartMap[r.StartMapId()] = append(byStartMap[r.StartMapId()], r)
+	}
+
+	results := make([][]Model, 0)
+	visited := map[_map.Id]bool{fromMapId: true}
+	var walk func(mapId _map.Id, path []Model)
+	walk = func(mapId _map.Id, path []Model) {
+		if len(path) == maxJourneyLegs {
+			return
+		}
+		for _, r := range byStartMap[mapId] {
+			next := r.DestinationMapId()
+			if visited[next] {
+				continue
+			}
+			p := append(append([]Model{}, path...), r)
+			if next == toMapId {
+				results = append(results, p)
+				continue
+			}
+			visited[next] = true
+			walk(next, p)
+			visited[next] = false
+		}
+	}
+	walk(fromMapId, nil)
+	return results
+}
+
+// catchableTrips returns the dated trips of the route a traveller ready at the given time can catch within the
+// horizon, in the order they open boarding.
+func catchableTrips(r Model, ready time.Time) []TripScheduleModel {
+	results := make([]TripScheduleModel, 0)
+	for _, trip := range r.TripsBetween(ready.Add(-r.BoardingWindowDuration()), ready.Add(journeyHorizon)) {
+		if trip.BoardingClosed().After(ready) {
+			results = append(results, trip)
+		}
+	}
+	return results
+}
+
+func newJourneyLeg(r Model, trip TripScheduleModel, ready time.Time) JourneyLegModel {
+	boardAt := trip.BoardingOpen()
+	if ready.After(boardAt) {
+		boardAt = ready
+	}
+	return JourneyLegModel{route: r, trip: trip, boardAt: boardAt, connection: boardAt.Sub(ready)}
+}
@@ -0,0 +1,64 @@
+package transport
+
+import (
+	"atlas-transports/clock"
+	"testing"
+	"time"
+
+	_map "github.com/Chronicle20/atlas-constants/map"
+	"github.com/stretchr/testify/assert"
+)
+
+func journeyRoutes() []Model {
+	ellinia := NewBuilder("Ellinia to Orbis").
+		SetStartMapId(101000300).
+		SetStagingMapId(101000301).
+		SetEnRouteMapIds([]_map.Id{200090010}).
+		SetDestinationMapId(200000100).
+		SetBoardingWindowDuration(4 * time.Minute).
+		SetPreDepartureDuration(1 * time.Minute).
+		SetTravelDuration(10 * time.Minute).
+		SetCycleInterval(time.Hour).
+		Build()
+	ludibrium := NewBuilder("Orbis to Ludibrium").
+		SetStartMapId(200000100).
+		SetStagingMapId(200000122).
+		SetEnRouteMapIds([]_map.Id{200090100}).
+		SetDestinationMapId(220000100).
+		SetBoardingWindowDuration(5 * time.Minute).
+		SetPreDepartureDuration(1 * time.Minute).
+		SetTravelDuration(4 * time.Minute).
+		SetCycleInterval(30 * time.Minute).
+		Build()
+	return ScheduleRoutes(clock.Fixed(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)), []Model{ellinia, ludibrium}, nil)
+}
+
+func TestPlanJourneys(t *testing.T) {
+	departAfter := time.Date(2025, 3, 1, 9, 50, 0, 0, time.UTC)
+	js := PlanJourneys(journeyRoutes(), 101000300, 220000100, departAfter, 2)
+
+	assert.Len(t, js, 2)
+	assert.Len(t, js[0].Legs(), 2)
+	assert.Equal(t, time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC), js[0].BoardAt())
+	assert.Equal(t, 10*time.Minute, js[0].Legs()[0].Connection())
+	assert.Equal(t, time.Date(2025, 3, 1, 10, 30, 0, 0, time.UTC), js[0].Legs()[1].BoardAt())
+	assert.Equal(t, 15*time.Minute, js[0].Legs()[1].Connection())
+	assert.Equal(t, time.Date(2025, 3, 1, 10, 40, 0, 0, time.UTC), js[0].Arrival())
+	assert.Equal(t, time.Date(2025, 3, 1, 11, 40, 0, 0, time.UTC), js[1].Arrival())
+}
+
+func TestPlanJourneys_BoardingOpen(t *testing.T) {
+	departAfter := time.Date(2025, 3, 1, 10, 2, 0, 0, time.UTC)
+	js := PlanJourneys(journeyRoutes(), 101000300, 200000100, departAfter, 1)
+
+	assert.Len(t, js, 1)
+	assert.Equal(t, departAfter, js[0].BoardAt())
+	assert.Equal(t, time.Duration(0), js[0].Legs()[0].Connection())
+	assert.Equal(t, time.Date(2025, 3, 1, 10, 15, 0, 0, time.UTC), js[0].Arrival())
+}
+
+func TestPlanJourneys_Unreachable(t *testing.T) {
+	departAfter := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	assert.Empty(t, PlanJourneys(journeyRoutes(), 220000100, 101000300, departAfter, 5))
+	assert.Empty(t, PlanJourneys(journeyRoutes(), 101000300, 101000300, departAfter, 5))
+}
@@ -0,0 +1,86 @@
+package transport
+
+import (
+	"time"
+
+	_map "github.com/Chronicle20/atlas-constants/map"
+	"github.com/Chronicle20/atlas-constants/world"
+	"github.com/google/uuid"
+)
+
+// LogoutPolicy determines what happens to a character who logs out in a staging or en-route map.
+type LogoutPolicy string
+
+const (
+	// LogoutReturnToStart warps the character back to the route's start map
+	LogoutReturnToStart LogoutPolicy = "return_to_start"
+
+	// LogoutSendToDestination warps the character ahead to the route's destination map
+	LogoutSendToDestination LogoutPolicy = "send_to_destination"
+
+	// LogoutPendingRelocation leaves the character in place, and relocates them at next login based on the route
+	// state at that time
+	LogoutPendingRelocation LogoutPolicy = "pending_relocation"
+
+	// LogoutStay leaves the character in place
+	LogoutStay LogoutPolicy = "stay"
+)
+
+// logoutPolicies is every logout policy a route may configure.
+var logoutPolicies = []LogoutPolicy{LogoutReturnToStart, LogoutSendToDestination, LogoutPendingRelocation, LogoutStay}
+
+// PendingRelocationModel records a character who logged out aboard a route and is to be relocated at next login
+type PendingRelocationModel struct {
+	characterId uint32
+	routeId     uuid.UUID
+	worldId     world.Id
+	mapId       _map.Id
+	state       RouteState
+	recordedAt  time.Time
+}
+
+// NewPendingRelocationModel creates a new pending relocation
+func NewPendingRelocationModel(characterId uint32, routeId uuid.UUID, worldId world.Id, mapId _map.Id, state RouteState, recordedAt time.Time) PendingRelocationModel {
+	return PendingRelocationModel{
+		characterId: characterId,
+		routeId:     routeId,
+		worldId:     worldId,
+		mapId:       mapId,
+		state:       state,
+		recordedAt:  recordedAt,
+	}
+}
+
+// CharacterId returns the ID of the character
+func (m PendingRelocationModel) CharacterId() uint32 {
+	return m.characterId
+}
+
+// RouteId returns the ID of the route the character logged out on
+func (m PendingRelocationModel) RouteId() uuid.UUID {
+	return m.routeId
+}
+
+// WorldId returns the world the character logged out in
+func (m PendingRelocationModel) WorldId() world.Id {
+	return m.worldId
+}
+
+// MapId returns the map the character logged out in
+func (m PendingRelocationModel) MapId() _map.Id {
+	return m.mapId
+}
+
+// State returns the route state at the time of logout
+func (m PendingRelocationModel) State() RouteState {
+	return m.state
+}
+
+// RecordedAt returns the time of logout
+func (m PendingRelocationModel) RecordedAt() time.Time {
+	return m.recordedAt
+}
+
+func (m PendingRelocationModel) expired(now time.Time) bool {
+	return now.Sub(m.recordedAt) > pendingRelocationRetention
+}
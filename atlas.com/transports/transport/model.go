@@ -1,7 +1,10 @@
 package transport
 
 import (
+	"github.com/Chronicle20/atlas-constants/channel"
 	_map "github.com/Chronicle20/atlas-constants/map"
+	"github.com/Chronicle20/atlas-constants/world"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,6 +25,12 @@ type Model struct {
 	preDepartureDuration   time.Duration
 	travelDuration         time.Duration
 	cycleInterval          time.Duration
+	departureWarp          WarpStrategy
+	arrivalWarp            WarpStrategy
+	logoutPolicies         map[RouteState]LogoutPolicy
+	enablement             []WorldEnablementModel
+	channelStagger         time.Duration
+	updatedAt              time.Time
 }
 
 // Id returns the route ID
@@ -79,6 +88,119 @@ func (m Model) CycleInterval() time.Duration {
 	return m.cycleInterval
 }
 
+// DepartureWarp returns the strategy choosing the portal passengers land on in the en-route map
+func (m Model) DepartureWarp() WarpStrategy {
+	return m.departureWarp
+}
+
+// ArrivalWarp returns the strategy choosing the portal passengers land on in the destination map
+func (m Model) ArrivalWarp() WarpStrategy {
+	return m.arrivalWarp
+}
+
+// LogoutPolicies returns the configured logout policy of each route state
+func (m Model) LogoutPolicies() map[RouteState]LogoutPolicy {
+	return m.logoutPolicies
+}
+
+// LogoutPolicyFor returns the policy applied to characters logging out aboard the route while it is in the given state.
+// Characters are returned to the start map unless configured otherwise.
+func (m Model) LogoutPolicyFor(state RouteState) LogoutPolicy {
+	if policy, ok := m.logoutPolicies[state]; ok {
+		return policy
+	}
+	return LogoutReturnToStart
+}
+
+// Enablement returns the worlds, and channels within them, the route runs in. An empty enablement runs the route
+// everywhere.
+func (m Model) Enablement() []WorldEnablementModel {
+	return m.enablement
+}
+
+// ChannelStagger returns the phase offset between consecutive channels, applied to the passenger warps of a transition.
+func (m Model) ChannelStagger() time.Duration {
+	return m.channelStagger
+}
+
+// UpdatedAt returns the clock reading the state of the route was last evaluated at, or the zero time if it has not been.
+func (m Model) UpdatedAt() time.Time {
+	return m.updatedAt
+}
+
+// EnabledIn reports whether the route runs in the channel of the world.
+func (m Model) EnabledIn(worldId world.Id, channelId channel.Id) bool {
+	if len(m.enablement) == 0 {
+		return true
+	}
+	for _, e := range m.enablement {
+		if e.Includes(worldId, channelId) {
+			return true
+		}
+	}
+	return false
+}
+
+// ChannelOffset returns how long after a transition the side effects of the channel at the given rank, among the
+// route's channels of its world ordered by id, are issued.
+func (m Model) ChannelOffset(rank int) time.Duration {
+	return time.Duration(rank) * m.channelStagger
+}
+
+// MapIds returns the start, staging, en-route and destination maps of the route.
+func (m Model) MapIds() []_map.Id {
+	mapIds := []_map.Id{m.StartMapId(), m.StagingMapId()}
+	mapIds = append(mapIds, m.EnRouteMapIds()...)
+	return append(mapIds, m.DestinationMapId())
+}
+
+// StrandedTarget reports whether a character in the given map is stranded according to the current route state, and
+// if so, where they belong. Characters in the staging map outside of boarding never departed and belong in the start
+// map. Characters in an en-route map outside of transit belong to a trip which has arrived, and belong in the
+// destination map.
+func (m Model) StrandedTarget(mapId _map.Id) (_map.Id, bool) {
+	if mapId == m.StagingMapId() && m.State() != OpenEntry && m.State() != LockedEntry {
+		return m.StartMapId(), true
+	}
+	for _, enRouteMapId := range m.EnRouteMapIds() {
+		if mapId == enRouteMapId && m.State() != InTransit {
+			return m.DestinationMapId(), true
+		}
+	}
+	return 0, false
+}
+
+// LoginTarget reports whether a character logging in to the given map is stranded according to the current route
+// state, and if so, where the route's logout policy for that state sends them. Policies which leave characters in place
+// cannot keep a stranded character there, so send them wherever the route state says they belong.
+func (m Model) LoginTarget(mapId _map.Id) (_map.Id, bool) {
+	targetMapId, stranded := m.StrandedTarget(mapId)
+	if !stranded {
+		return 0, false
+	}
+	switch m.LogoutPolicyFor(m.State()) {
+	case LogoutReturnToStart:
+		return m.StartMapId(), true
+	case LogoutSendToDestination:
+		return m.DestinationMapId(), true
+	default:
+		return targetMapId, true
+	}
+}
+
+// WarpStrategyFor returns the strategy for warping passengers of the route into the given map.
+func (m Model) WarpStrategyFor(mapId _map.Id) WarpStrategy {
+	if mapId == m.DestinationMapId() {
+		return m.ArrivalWarp()
+	}
+	for _, enRouteMapId := range m.EnRouteMapIds() {
+		if mapId == enRouteMapId {
+			return m.DepartureWarp()
+		}
+	}
+	return RandomSpawnWarpStrategy()
+}
+
 func (m Model) Builder() *Builder {
 	return NewBuilder(m.Name()).
 		SetId(m.Id()).
@@ -92,12 +214,18 @@ func (m Model) Builder() *Builder {
 		SetBoardingWindowDuration(m.boardingWindowDuration).
 		SetPreDepartureDuration(m.preDepartureDuration).
 		SetTravelDuration(m.travelDuration).
-		SetCycleInterval(m.cycleInterval)
+		SetCycleInterval(m.cycleInterval).
+		SetDepartureWarp(m.departureWarp).
+		SetArrivalWarp(m.arrivalWarp).
+		SetLogoutPolicies(m.logoutPolicies).
+		SetEnablement(m.enablement).
+		SetChannelStagger(m.channelStagger).
+		SetUpdatedAt(m.updatedAt)
 }
 
 func (m Model) UpdateState(now time.Time) (Model, bool) {
 	newState := m.processStateChange(now)
-	return m.Builder().SetState(newState).Build(), m.State() != newState
+	return m.Builder().SetState(newState).SetUpdatedAt(now).Build(), m.State() != newState
 }
 
 func (m Model) processStateChange(now time.Time) RouteState {
@@ -107,7 +235,8 @@ func (m Model) processStateChange(now time.Time) RouteState {
 	var futureTrip *TripScheduleModel
 	var arrivedTrip *TripScheduleModel
 
-	// Get the current time of day
+	// Get the current time of day. Schedules are laid out in UTC, so the clock's reading is too.
+	now = now.UTC()
 	nowTimeOfDay := time.Date(0, 1, 1, now.Hour(), now.Minute(), now.Second(), now.Nanosecond(), time.UTC)
 
 	for i := range m.Schedule() {
@@ -201,6 +330,91 @@ func (m Model) processStateChange(now time.Time) RouteState {
 	}
 }
 
+// LastDeparture returns the trip which departed most recently as of now, together with the instant it departed.
+func (m Model) LastDeparture(now time.Time) (TripScheduleModel, time.Time, bool) {
+	var last TripScheduleModel
+	var departedAt time.Time
+	found := false
+	for _, trip := range m.Schedule() {
+		if trip.RouteId() != m.Id() {
+			continue
+		}
+		d := occurrenceOnOrBefore(trip.Departure(), now)
+		if !found || d.After(departedAt) {
+			last = trip
+			departedAt = d
+			found = true
+		}
+	}
+	return last, departedAt, found
+}
+
+// TripsBetween returns the trips of the route which open boarding within [from, to), dated to the days they run on.
+// Trip times of day are resolved in UTC, as the state machine does with the clock's reading.
+func (m Model) TripsBetween(from time.Time, to time.Time) []TripScheduleModel {
+	results := make([]TripScheduleModel, 0)
+	from = from.UTC()
+	first := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	for day := first; day.Before(to); day = day.AddDate(0, 0, 1) {
+		for _, trip := range m.Schedule() {
+			if trip.RouteId() != m.Id() {
+				continue
+			}
+			bo := onDayOf(trip.BoardingOpen(), day)
+			if bo.Before(from) || !bo.Before(to) {
+				continue
+			}
+			results = append(results, trip.Builder().
+				SetBoardingOpen(bo).
+				SetBoardingClosed(bo.Add(trip.BoardingClosed().Sub(trip.BoardingOpen()))).
+				SetDeparture(bo.Add(trip.Departure().Sub(trip.BoardingOpen()))).
+				SetArrival(bo.Add(trip.Arrival().Sub(trip.BoardingOpen()))).
+				Build())
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].BoardingOpen().Before(results[j].BoardingOpen())
+	})
+	return results
+}
+
+// LastTransitionAt returns the most recent instant, as of now, at which the schedule moved the route between states.
+func (m Model) LastTransitionAt(now time.Time) time.Time {
+	var last time.Time
+	for _, trip := range m.Schedule() {
+		if trip.RouteId() != m.Id() {
+			continue
+		}
+		for _, t := range []time.Time{trip.BoardingOpen(), trip.BoardingClosed(), trip.Departure(), trip.Arrival()} {
+			o := occurrenceOnOrBefore(t, now)
+			if o.After(last) {
+				last = o
+			}
+		}
+	}
+	return last
+}
+
+// NextTransitionAt returns the next instant, after now, at which the schedule moves the route between states. The
+// second result is false when the route has no schedule.
+func (m Model) NextTransitionAt(now time.Time) (time.Time, bool) {
+	var next time.Time
+	found := false
+	for _, trip := range m.Schedule() {
+		if trip.RouteId() != m.Id() {
+			continue
+		}
+		for _, t := range []time.Time{trip.BoardingOpen(), trip.BoardingClosed(), trip.Departure(), trip.Arrival()} {
+			o := occurrenceAfter(t, now)
+			if !found || o.Before(next) {
+				next = o
+				found = true
+			}
+		}
+	}
+	return next, found
+}
+
 func (m Model) State() RouteState {
 	return m.state
 }
@@ -224,16 +438,25 @@ type Builder struct {
 	preDepartureDuration   time.Duration
 	travelDuration         time.Duration
 	cycleInterval          time.Duration
+	departureWarp          WarpStrategy
+	arrivalWarp            WarpStrategy
+	logoutPolicies         map[RouteState]LogoutPolicy
+	enablement             []WorldEnablementModel
+	channelStagger         time.Duration
+	updatedAt              time.Time
 }
 
 // NewBuilder creates a new builder for Model
 func NewBuilder(name string) *Builder {
 	return &Builder{
-		id:            uuid.New(),
-		name:          name,
-		enRouteMapIds: []_map.Id{},
-		state:         OutOfService,
-		schedule:      []TripScheduleModel{},
+		id:             uuid.New(),
+		name:           name,
+		enRouteMapIds:  []_map.Id{},
+		state:          OutOfService,
+		schedule:       []TripScheduleModel{},
+		departureWarp:  RandomSpawnWarpStrategy(),
+		arrivalWarp:    RandomSpawnWarpStrategy(),
+		logoutPolicies: map[RouteState]LogoutPolicy{},
 	}
 }
 
@@ -309,6 +532,35 @@ func (b *Builder) SetCycleInterval(cycleInterval time.Duration) *Builder {
 	return b
 }
 
+// SetDepartureWarp sets the strategy choosing the portal passengers land on in the en-route map
+func (b *Builder) SetDepartureWarp(departureWarp WarpStrategy) *Builder {
+	b.departureWarp = departureWarp
+	return b
+}
+
+// SetArrivalWarp sets the strategy choosing the portal passengers land on in the destination map
+func (b *Builder) SetArrivalWarp(arrivalWarp WarpStrategy) *Builder {
+	b.arrivalWarp = arrivalWarp
+	return b
+}
+
+// SetLogoutPolicies sets the logout policy of each route state
+func (b *Builder) SetLogoutPolicies(logoutPolicies map[RouteState]LogoutPolicy) *Builder {
+	b.logoutPolicies = logoutPolicies
+	return b
+}
+
+// SetLogoutPolicy sets the logout policy of a single route state
+func (b *Builder) SetLogoutPolicy(state RouteState, policy LogoutPolicy) *Builder {
+	policies := make(map[RouteState]LogoutPolicy)
+	for s, p := range b.logoutPolicies {
+		policies[s] = p
+	}
+	policies[state] = policy
+	b.logoutPolicies = policies
+	return b
+}
+
 // Build builds the Model
 func (b *Builder) Build() Model {
 	return Model{
@@ -325,9 +577,33 @@ func (b *Builder) Build() Model {
 		preDepartureDuration:   b.preDepartureDuration,
 		travelDuration:         b.travelDuration,
 		cycleInterval:          b.cycleInterval,
+		departureWarp:          b.departureWarp,
+		arrivalWarp:            b.arrivalWarp,
+		logoutPolicies:         b.logoutPolicies,
+		enablement:             b.enablement,
+		channelStagger:         b.channelStagger,
+		updatedAt:              b.updatedAt,
 	}
 }
 
+// SetEnablement sets the worlds, and channels within them, the route runs in
+func (b *Builder) SetEnablement(enablement []WorldEnablementModel) *Builder {
+	b.enablement = enablement
+	return b
+}
+
+// SetChannelStagger sets the phase offset between consecutive channels
+func (b *Builder) SetChannelStagger(channelStagger time.Duration) *Builder {
+	b.channelStagger = channelStagger
+	return b
+}
+
+// SetUpdatedAt sets the clock reading the state of the route was last evaluated at
+func (b *Builder) SetUpdatedAt(updatedAt time.Time) *Builder {
+	b.updatedAt = updatedAt
+	return b
+}
+
 func (b *Builder) SetState(state RouteState) *Builder {
 	b.state = state
 	return b
@@ -362,6 +638,7 @@ func NewSharedVesselModel(
 ) SharedVesselModel {
 	return SharedVesselModel{
 		id:              id,
+		name:            name,
 		routeAID:        routeAID,
 		routeBID:        routeBID,
 		turnaroundDelay: turnaroundDelay,
@@ -373,6 +650,11 @@ func (m SharedVesselModel) Id() uuid.UUID {
 	return m.id
 }
 
+// Name returns the shared vessel name
+func (m SharedVesselModel) Name() string {
+	return m.name
+}
+
 // RouteAID returns the ID of route A
 func (m SharedVesselModel) RouteAID() uuid.UUID {
 	return m.routeAID
@@ -569,3 +851,125 @@ func (b *TripScheduleBuilder) Build() TripScheduleModel {
 		b.arrival,
 	)
 }
+
+// PendingTransitionModel describes a route transition whose side effects failed to publish and which awaits a retry
+type PendingTransitionModel struct {
+	routeId       uuid.UUID
+	fromState     RouteState
+	toState       RouteState
+	attempts      int
+	firstFailedAt time.Time
+	lastFailedAt  time.Time
+	nextAttemptAt time.Time
+	lastError     string
+}
+
+// RouteId returns the ID of the route
+func (m PendingTransitionModel) RouteId() uuid.UUID {
+	return m.routeId
+}
+
+// FromState returns the committed state of the route
+func (m PendingTransitionModel) FromState() RouteState {
+	return m.fromState
+}
+
+// ToState returns the state the route is attempting to enter
+func (m PendingTransitionModel) ToState() RouteState {
+	return m.toState
+}
+
+// Attempts returns the number of failed attempts
+func (m PendingTransitionModel) Attempts() int {
+	return m.attempts
+}
+
+// FirstFailedAt returns the time of the first failed attempt
+func (m PendingTransitionModel) FirstFailedAt() time.Time {
+	return m.firstFailedAt
+}
+
+// LastFailedAt returns the time of the most recent failed attempt
+func (m PendingTransitionModel) LastFailedAt() time.Time {
+	return m.lastFailedAt
+}
+
+// NextAttemptAt returns the earliest time of the next attempt
+func (m PendingTransitionModel) NextAttemptAt() time.Time {
+	return m.nextAttemptAt
+}
+
+// LastError returns the error of the most recent failed attempt
+func (m PendingTransitionModel) LastError() string {
+	return m.lastError
+}
+
+// TransitionRecordModel describes a single attempt of a route to move between states
+type TransitionRecordModel struct {
+	id               uuid.UUID
+	routeId          uuid.UUID
+	fromState        RouteState
+	toState          RouteState
+	scheduledAt      time.Time
+	occurredAt       time.Time
+	tripOccurrenceId string
+	passengersMoved  int
+	attempts         int
+	err              string
+}
+
+// Id returns the ID of the record
+func (m TransitionRecordModel) Id() uuid.UUID {
+	return m.id
+}
+
+// RouteId returns the ID of the route
+func (m TransitionRecordModel) RouteId() uuid.UUID {
+	return m.routeId
+}
+
+// FromState returns the state the route was in
+func (m TransitionRecordModel) FromState() RouteState {
+	return m.fromState
+}
+
+// ToState returns the state the route moved, or attempted to move, to
+func (m TransitionRecordModel) ToState() RouteState {
+	return m.toState
+}
+
+// ScheduledAt returns the time the schedule called for the transition
+func (m TransitionRecordModel) ScheduledAt() time.Time {
+	return m.scheduledAt
+}
+
+// OccurredAt returns the time the transition was attempted
+func (m TransitionRecordModel) OccurredAt() time.Time {
+	return m.occurredAt
+}
+
+// TripOccurrenceId returns the trip occurrence the transition belongs to, if the route has departed before
+func (m TransitionRecordModel) TripOccurrenceId() string {
+	return m.tripOccurrenceId
+}
+
+// PassengersMoved returns the number of warps published for the transition, including those of staggered channels
+func (m TransitionRecordModel) PassengersMoved() int {
+	return m.passengersMoved
+}
+
+// Attempts returns the number of consecutive attempts the record stands for. Repeated failures of the same transition
+// are collapsed into a single record.
+func (m TransitionRecordModel) Attempts() int {
+	return m.attempts
+}
+
+// Error returns the reason the transition failed, or an empty string if it succeeded
+func (m TransitionRecordModel) Error() string {
+	return m.err
+}
+
+// Succeeded returns true if the side effects of the transition were published
+func (m TransitionRecordModel) Succeeded() bool {
+	return m.err == ""
+}
@@ -0,0 +1,332 @@
+package transport
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	_map "github.com/Chronicle20/atlas-constants/map"
+	"github.com/google/uuid"
+)
+
+const (
+	// NetworkEdgeRoute is an edge following a route from its start map to its destination map.
+	NetworkEdgeRoute = "route"
+	// NetworkEdgeVessel is an edge joining the maps a shared vessel shuttles between.
+	NetworkEdgeVessel = "vessel"
+)
+
+// NetworkNodeModel is a map which routes start or end at.
+type NetworkNodeModel struct {
+	mapId  _map.Id
+	region int
+}
+
+// MapId returns the ID of the map
+func (m NetworkNodeModel) MapId() _map.Id {
+	return m.mapId
+}
+
+// Region returns the index of the connected region of the network the map belongs to
+func (m NetworkNodeModel) Region() int {
+	return m.region
+}
+
+// NetworkEdgeModel is a route or shared vessel connecting two maps.
+type NetworkEdgeModel struct {
+	id              uuid.UUID
+	kind            string
+	name            string
+	sourceMapId     _map.Id
+	targetMapId     _map.Id
+	tripDuration    time.Duration
+	tripsPerDay     int
+	turnaroundDelay time.Duration
+}
+
+// Id returns the ID of the route or shared vessel
+func (m NetworkEdgeModel) Id() uuid.UUID {
+	return m.id
+}
+
+// Kind returns whether the edge is a route or a shared vessel
+func (m NetworkEdgeModel) Kind() string {
+	return m.kind
+}
+
+// Name returns the name of the route or shared vessel
+func (m NetworkEdgeModel) Name() string {
+	return m.name
+}
+
+// SourceMapId returns the map the edge leaves from
+func (m NetworkEdgeModel) SourceMapId() _map.Id {
+	return m.sourceMapId
+}
+
+// TargetMapId returns the map the edge arrives at
+func (m NetworkEdgeModel) TargetMapId() _map.Id {
+	return m.targetMapId
+}
+
+// TripDuration returns the time from boarding open to arrival. For a shared vessel, it is the time of a round trip
+// including both turnarounds.
+func (m NetworkEdgeModel) TripDuration() time.Duration {
+	return m.tripDuration
+}
+
+// TripsPerDay returns how many trips complete in a day. For a shared vessel, it counts round trips.
+func (m NetworkEdgeModel) TripsPerDay() int {
+	return m.tripsPerDay
+}
+
+// Headway returns the average time between trips, or zero when there are none
+func (m NetworkEdgeModel) Headway() time.Duration {
+	if m.tripsPerDay == 0 {
+		return 0
+	}
+	return 24 * time.Hour / time.Duration(m.tripsPerDay)
+}
+
+// TurnaroundDelay returns how long a shared vessel waits between trips
+func (m NetworkEdgeModel) TurnaroundDelay() time.Duration {
+	return m.turnaroundDelay
+}
+
+// NetworkModel is the transport network of a tenant.
+type NetworkModel struct {
+	nodes   []NetworkNodeModel
+	edges   []NetworkEdgeModel
+	regions int
+}
+
+// Nodes returns the maps of the network, ordered by id
+func (m NetworkModel) Nodes() []NetworkNodeModel {
+	return m.nodes
+}
+
+// Edges returns the routes and shared vessels of the network
+func (m NetworkModel) Edges() []NetworkEdgeModel {
+	return m.edges
+}
+
+// Regions returns the number of regions of the network which are not connected to one another
+func (m NetworkModel) Regions() int {
+	return m.regions
+}
+
+// BuildNetwork describes the routes and shared vessels as a graph of the maps routes start and end at. Maps are grouped
+// into regions connected by any edge, regardless of its direction.
+func BuildNetwork(routes []Model, vessels []SharedVesselModel) NetworkModel {
+	byId := make(map[uuid.UUID]Model)
+	parent := make(map[_map.Id]_map.Id)
+	var find func(mapId _map.Id) _map.Id
+	find = func(mapId _map.Id) _map.Id {
+		if parent[mapId] != mapId {
+			parent[mapId] = find(parent[mapId])
+		}
+		return parent[mapId]
+	}
+	join := func(a _map.Id, b _map.Id) {
+		for _, mapId := range []_map.Id{a, b} {
+			if _, ok := parent[mapId]; !ok {
+				parent[mapId] = mapId
+			}
+		}
+		parent[find(a)] = find(b)
+	}
+
+	edges := make([]NetworkEdgeModel, 0)
+	for _, r := range routes {
+		byId[r.Id()] = r
+		join(r.StartMapId(), r.DestinationMapId())
+		edges = append(edges, NetworkEdgeModel{
+			id:           r.Id(),
+			kind:         NetworkEdgeRoute,
+			name:         r.Name(),
+			sourceMapId:  r.StartMapId(),
+			targetMapId:  r.DestinationMapId(),
+			tripDuration: r.BoardingWindowDuration() + r.PreDepartureDuration() + r.TravelDuration(),
+			tripsPerDay:  tripsOf(r),
+		})
+	}
+	for _, v := range vessels {
+		a, aok := byId[v.RouteAID()]
+		b, bok := byId[v.RouteBID()]
+		if !aok || !bok {
+			continue
+		}
+		join(a.StartMapId(), b.StartMapId())
+		edges = append(edges, NetworkEdgeModel{
+			id:              v.Id(),
+			kind:            NetworkEdgeVessel,
+			name:            v.Name(),
+			sourceMapId:     a.StartMapId(),
+			targetMapId:     b.StartMapId(),
+			tripDuration:    a.BoardingWindowDuration() + a.PreDepartureDuration() + a.TravelDuration() + b.BoardingWindowDuration() + b.PreDepartureDuration() + b.TravelDuration() + 2*v.TurnaroundDelay(),
+			tripsPerDay:     min(tripsOf(a), tripsOf(b)),
+			turnaroundDelay: v.TurnaroundDelay(),
+		})
+	}
+
+	mapIds := make([]_map.Id, 0, len(parent))
+	for mapId := range parent {
+		mapIds = append(mapIds, mapId)
+	}
+	sort.Slice(mapIds, func(i, j int) bool {
+		return mapIds[i] < mapIds[j]
+	})
+	regions := make(map[_map.Id]int)
+	nodes := make([]NetworkNodeModel, 0, len(mapIds))
+	for _, mapId := range mapIds {
+		root := find(mapId)
+		if _, ok := regions[root]; !ok {
+			regions[root] = len(regions)
+		}
+		nodes = append(nodes, NetworkNodeModel{mapId: mapId, region: regions[root]})
+	}
+	return NetworkModel{nodes: nodes, edges: edges, regions: len(regions)}
+}
+
+// tripsOf counts the trips of the route in its daily schedule.
+func tripsOf(r Model) int {
+	count := 0
+	for _, trip := range r.Schedule() {
+		if trip.RouteId() == r.Id() {
+			count++
+		}
+	}
+	return count
+}
+
+// WriteDOT writes the network as a Graphviz DOT digraph. Shared vessels are drawn as dashed edges in both directions,
+// and each region is clustered.
+func WriteDOT(w io.Writer) func(n NetworkModel) error {
+	return func(n NetworkModel) error {
+		bw := bufio.NewWriter(w)
+		_, _ = bw.WriteString("digraph transports {\n")
+		_, _ = bw.WriteString("  rankdir=LR;\n")
+		_, _ = bw.WriteString("  node [shape=box];\n")
+		for region := 0; region < n.Regions(); region++ {
+			_, _ = fmt.Fprintf(bw, "  subgraph cluster_%d {\n", region)
+			_, _ = fmt.Fprintf(bw, "    label=%s;\n", dotQuote(fmt.Sprintf("Region %d", region)))
+			for _, node := range n.Nodes() {
+				if node.Region() == region {
+					_, _ = fmt.Fprintf(bw, "    %d [label=%s];\n", node.MapId(), dotQuote(fmt.Sprintf("Map %d", node.MapId())))
+				}
+			}
+			_, _ = bw.WriteString("  }\n")
+		}
+		for _, e := range n.Edges() {
+			label := dotQuote(fmt.Sprintf("%s\n%s, %d/day", e.Name(), e.TripDuration(), e.TripsPerDay()))
+			if e.Kind() == NetworkEdgeVessel {
+				_, _ = fmt.Fprintf(bw, "  %d -> %d [label=%s, style=dashed, dir=both];\n", e.SourceMapId(), e.TargetMapId(), label)
+				continue
+			}
+			_, _ = fmt.Fprintf(bw, "  %d -> %d [label=%s];\n", e.SourceMapId(), e.TargetMapId(), label)
+		}
+		_, _ = bw.WriteString("}\n")
+		return bw.Flush()
+	}
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	Id       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	Id          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	Id   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Id     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// WriteGraphML writes the network as a GraphML document. Durations are written in seconds.
+func WriteGraphML(w io.Writer) func(n NetworkModel) error {
+	return func(n NetworkModel) error {
+		doc := graphML{
+			Xmlns: "http://graphml.graphdrawing.org/xmlns",
+			Keys: []graphMLKey{
+				{Id: "region", For: "node", AttrName: "region", AttrType: "int"},
+				{Id: "kind", For: "edge", AttrName: "kind", AttrType: "string"},
+				{Id: "name", For: "edge", AttrName: "name", AttrType: "string"},
+				{Id: "tripDuration", For: "edge", AttrName: "tripDuration", AttrType: "double"},
+				{Id: "tripsPerDay", For: "edge", AttrName: "tripsPerDay", AttrType: "int"},
+				{Id: "headway", For: "edge", AttrName: "headway", AttrType: "double"},
+				{Id: "turnaroundDelay", For: "edge", AttrName: "turnaroundDelay", AttrType: "double"},
+			},
+			Graph: graphMLGraph{Id: "transports", EdgeDefault: "directed"},
+		}
+		for _, node := range n.Nodes() {
+			doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{
+				Id:   strconv.Itoa(int(node.MapId())),
+				Data: []graphMLData{{Key: "region", Value: strconv.Itoa(node.Region())}},
+			})
+		}
+		for _, e := range n.Edges() {
+			doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+				Id:     e.Id().String(),
+				Source: strconv.Itoa(int(e.SourceMapId())),
+				Target: strconv.Itoa(int(e.TargetMapId())),
+				Data: []graphMLData{
+					{Key: "kind", Value: e.Kind()},
+					{Key: "name", Value: e.Name()},
+					{Key: "tripDuration", Value: seconds(e.TripDuration())},
+					{Key: "tripsPerDay", Value: strconv.Itoa(e.TripsPerDay())},
+					{Key: "headway", Value: seconds(e.Headway())},
+					{Key: "turnaroundDelay", Value: seconds(e.TurnaroundDelay())},
+				},
+			})
+		}
+		if _, err := io.WriteString(w, xml.Header); err != nil {
+			return err
+		}
+		e := xml.NewEncoder(w)
+		e.Indent("", "  ")
+		if err := e.Encode(doc); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, "\n")
+		return err
+	}
+}
+
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
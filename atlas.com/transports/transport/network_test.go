@@ -0,0 +1,46 @@
+package transport
+
+import (
+	"atlas-transports/clock"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	_map "github.com/Chronicle20/atlas-constants/map"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildNetwork(t *testing.T) {
+	toOrbis := NewBuilder("Ellinia to Orbis").SetStartMapId(101000300).SetStagingMapId(101000301).SetEnRouteMapIds([]_map.Id{200090010}).SetDestinationMapId(200000100).
+		SetBoardingWindowDuration(4 * time.Minute).SetPreDepartureDuration(time.Minute).SetTravelDuration(10 * time.Minute).Build()
+	toEllinia := NewBuilder("Orbis to Ellinia").SetStartMapId(200000100).SetStagingMapId(200000112).SetEnRouteMapIds([]_map.Id{200090000}).SetDestinationMapId(101000300).
+		SetBoardingWindowDuration(4 * time.Minute).SetPreDepartureDuration(time.Minute).SetTravelDuration(10 * time.Minute).Build()
+	island := NewBuilder("Victoria to Rien").SetStartMapId(104000000).SetStagingMapId(200090060).SetDestinationMapId(140020300).
+		SetBoardingWindowDuration(time.Minute).SetTravelDuration(time.Minute).SetCycleInterval(time.Hour).Build()
+	vessel := NewSharedVesselBuilder().SetName("Ellinia Ferry").SetRouteAID(toOrbis.Id()).SetRouteBID(toEllinia.Id()).SetTurnaroundDelay(5 * time.Minute).Build()
+	routes := ScheduleRoutes(clock.Fixed(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)), []Model{toOrbis, toEllinia, island}, []SharedVesselModel{vessel})
+
+	n := BuildNetwork(routes, []SharedVesselModel{vessel})
+	assert.Equal(t, 2, n.Regions())
+	assert.Len(t, n.Nodes(), 4)
+	assert.Equal(t, _map.Id(101000300), n.Nodes()[0].MapId())
+	assert.NotEqual(t, n.Nodes()[0].Region(), n.Nodes()[1].Region())
+	assert.Len(t, n.Edges(), 4)
+
+	e := n.Edges()[3]
+	assert.Equal(t, NetworkEdgeVessel, e.Kind())
+	assert.Equal(t, 40*time.Minute, e.TripDuration())
+	assert.Equal(t, 36, e.TripsPerDay())
+	assert.Equal(t, 40*time.Minute, e.Headway())
+
+	var dot bytes.Buffer
+	assert.NoError(t, WriteDOT(&dot)(n))
+	assert.Contains(t, dot.String(), "101000300 -> 200000100 [label=\"Ellinia Ferry\\n40m0s, 36/day\", style=dashed, dir=both];")
+	assert.Equal(t, 2, strings.Count(dot.String(), "subgraph cluster_"))
+
+	var graphml bytes.Buffer
+	assert.NoError(t, WriteGraphML(&graphml)(n))
+	assert.Contains(t, graphml.String(), `<data key="tripDuration">2400</data>`)
+	assert.Equal(t, 4, strings.Count(graphml.String(), "<edge "))
+}
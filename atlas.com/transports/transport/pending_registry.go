@@ -0,0 +1,95 @@
+package transport
+
+import (
+	"sync"
+	"time"
+
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/google/uuid"
+)
+
+const (
+	transitionRetryBaseDelay = 1 * time.Second
+	transitionRetryMaxDelay  = 1 * time.Minute
+)
+
+type PendingTransitionRegistry struct {
+	mutex   sync.RWMutex
+	pending map[uuid.UUID]map[uuid.UUID]PendingTransitionModel
+}
+
+var pendingTransitionRegistry *PendingTransitionRegistry
+var pendingTransitionRegistryOnce sync.Once
+
+func getPendingTransitionRegistry() *PendingTransitionRegistry {
+	pendingTransitionRegistryOnce.Do(func() {
+		pendingTransitionRegistry = &PendingTransitionRegistry{}
+		pendingTransitionRegistry.pending = make(map[uuid.UUID]map[uuid.UUID]PendingTransitionModel)
+	})
+	return pendingTransitionRegistry
+}
+
+// Fail records a failed attempt of a transition, and schedules the next attempt with exponential backoff.
+func (r *PendingTransitionRegistry) Fail(t tenant.Model, routeId uuid.UUID, fromState RouteState, toState RouteState, err error, now time.Time) PendingTransitionModel {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, ok := r.pending[t.Id()]; !ok {
+		r.pending[t.Id()] = make(map[uuid.UUID]PendingTransitionModel)
+	}
+
+	pt, ok := r.pending[t.Id()][routeId]
+	if !ok {
+		pt = PendingTransitionModel{
+			routeId:       routeId,
+			firstFailedAt: now,
+		}
+	}
+	pt.fromState = fromState
+	pt.toState = toState
+	pt.attempts++
+	pt.lastFailedAt = now
+	pt.nextAttemptAt = now.Add(transitionRetryBackoff(pt.attempts))
+	pt.lastError = err.Error()
+	r.pending[t.Id()][routeId] = pt
+	return pt
+}
+
+func (r *PendingTransitionRegistry) Get(t tenant.Model, routeId uuid.UUID) (PendingTransitionModel, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	if tenantPending, ok := r.pending[t.Id()]; ok {
+		pt, ok := tenantPending[routeId]
+		return pt, ok
+	}
+	return PendingTransitionModel{}, false
+}
+
+func (r *PendingTransitionRegistry) GetAll(t tenant.Model) []PendingTransitionModel {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	results := make([]PendingTransitionModel, 0)
+	for _, pt := range r.pending[t.Id()] {
+		results = append(results, pt)
+	}
+	return results
+}
+
+func (r *PendingTransitionRegistry) Clear(t tenant.Model, routeId uuid.UUID) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if tenantPending, ok := r.pending[t.Id()]; ok {
+		delete(tenantPending, routeId)
+	}
+}
+
+// transitionRetryBackoff doubles the delay with every failed attempt, up to transitionRetryMaxDelay.
+func transitionRetryBackoff(attempts int) time.Duration {
+	d := transitionRetryBaseDelay
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= transitionRetryMaxDelay {
+			return transitionRetryMaxDelay
+		}
+	}
+	return d
+}
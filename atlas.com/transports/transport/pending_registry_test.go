@@ -0,0 +1,38 @@
+package transport
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPendingTransitionRegistry_FailBacksOff(t *testing.T) {
+	ten, _ := tenant.Register(uuid.New(), "GMS", 83, 1)
+	routeId := uuid.New()
+	now := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	r := getPendingTransitionRegistry()
+
+	pt := r.Fail(ten, routeId, LockedEntry, InTransit, errors.New("boom"), now)
+	assert.Equal(t, 1, pt.Attempts())
+	assert.Equal(t, now.Add(1*time.Second), pt.NextAttemptAt())
+
+	pt = r.Fail(ten, routeId, LockedEntry, InTransit, errors.New("boom"), now.Add(time.Second))
+	assert.Equal(t, 2, pt.Attempts())
+	assert.Equal(t, now, pt.FirstFailedAt())
+	assert.Equal(t, now.Add(3*time.Second), pt.NextAttemptAt())
+
+	assert.Len(t, r.GetAll(ten), 1)
+	r.Clear(ten, routeId)
+	_, ok := r.Get(ten, routeId)
+	assert.False(t, ok)
+}
+
+func TestTransitionRetryBackoff_Capped(t *testing.T) {
+	assert.Equal(t, 1*time.Second, transitionRetryBackoff(1))
+	assert.Equal(t, 8*time.Second, transitionRetryBackoff(4))
+	assert.Equal(t, transitionRetryMaxDelay, transitionRetryBackoff(20))
+}
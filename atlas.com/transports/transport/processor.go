@@ -3,19 +3,31 @@ package transport
 import (
 	"atlas-transports/channel"
 	"atlas-transports/character"
+	"atlas-transports/clock"
+	"atlas-transports/data/portal"
 	"atlas-transports/kafka/message"
+	character2 "atlas-transports/kafka/message/character"
 	"atlas-transports/kafka/message/transport"
 	"atlas-transports/kafka/producer"
-	_map "atlas-transports/map"
+	"atlas-transports/metrics"
+	"atlas-transports/occupancy"
+	"atlas-transports/throttle"
+	"atlas-transports/tracing"
 	"context"
 	"errors"
 	channel2 "github.com/Chronicle20/atlas-constants/channel"
 	"github.com/Chronicle20/atlas-constants/field"
 	map2 "github.com/Chronicle20/atlas-constants/map"
+	"github.com/Chronicle20/atlas-constants/world"
 	"github.com/Chronicle20/atlas-model/model"
 	"github.com/Chronicle20/atlas-tenant"
 	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -23,54 +35,127 @@ type Processor interface {
 	AddTenant(routes []Model, sharedVessels []SharedVesselModel) error
 	ByIdProvider(id uuid.UUID) model.Provider[Model]
 	AllRoutesProvider() model.Provider[[]Model]
+	ByMapIdProvider(mapId map2.Id, roles ...MapRole) model.Provider[[]Model]
 	UpdateRoutes() error
 	UpdateRouteAndEmit(route Model) error
-	WarpToRouteStartMapOnLogout(mb *message.Buffer) func(characterId uint32, f field.Model) error
-	WarpToRouteStartMapOnLogoutAndEmit(characterId uint32, f field.Model) error
+	PendingTransitionsProvider() model.Provider[[]PendingTransitionModel]
+	RecoverStrandedPassengers(mb *message.Buffer) func(route Model) error
+	RecoverStrandedPassengersAndEmit() error
+	RelocateOnLogout(mb *message.Buffer) func(characterId uint32, f field.Model) error
+	RelocateOnLogoutAndEmit(characterId uint32, f field.Model) error
+	RelocateOnLogin(mb *message.Buffer) func(characterId uint32, f field.Model) error
+	RelocateOnLoginAndEmit(characterId uint32, f field.Model) error
+	ReconcilePassenger(mb *message.Buffer) func(characterId uint32, f field.Model) error
+	ReconcilePassengerAndEmit(characterId uint32, f field.Model) error
+	SendChannelSnapshots(mb *message.Buffer) func(worldId world.Id, channelId channel2.Id) error
+	SendChannelSnapshotsAndEmit(worldId world.Id, channelId channel2.Id) error
+	SendObservationSnapshots(mb *message.Buffer) func(f field.Model) error
+	SendObservationSnapshotsAndEmit(f field.Model) error
+	OccupancyProvider(routeId uuid.UUID) model.Provider[[]occupancy.Model]
+	ReconcileOccupancy() error
+	HistoryProvider(routeId uuid.UUID, since time.Time, until time.Time) model.Provider[[]TransitionRecordModel]
+	ScheduleProvider(from time.Time, to time.Time) model.Provider[[]Model]
+	RouteScheduleProvider(routeId uuid.UUID, from time.Time, to time.Time) model.Provider[[]Model]
+	Timezone() *time.Location
+	NetworkProvider() model.Provider[NetworkModel]
+	QuarantineProvider() model.Provider[[]QuarantineModel]
+	JourneyProvider(fromMapId map2.Id, toMapId map2.Id, departAfter time.Time, limit int) model.Provider[[]JourneyModel]
+	SubscribeStream(lastEventId uint64, resume bool) (<-chan StreamEventModel, []StreamEventModel, func())
 }
 
+// tickDeadline is how long a pass of UpdateRoutes waits for its route updates to finish.
+const tickDeadline = 5 * time.Second
+
+// ErrTickDeadlineExceeded is returned by UpdateRoutes when its route updates did not finish within tickDeadline.
+var ErrTickDeadlineExceeded = errors.New("route updates did not finish within the tick deadline")
+
+// recoveryGracePeriod is how long after a transition the recovery sweep leaves a route alone, so that warps which were
+// just published are not mistaken for stranded passengers.
+const recoveryGracePeriod = 30 * time.Second
+
 // ProcessorImpl handles business logic for transport routes
 type ProcessorImpl struct {
-	l     logrus.FieldLogger
-	ctx   context.Context
-	t     tenant.Model
-	p     producer.Provider
-	chanP channel.Processor
-	charP character.Processor
-	mp    _map.Processor
+	l       logrus.FieldLogger
+	ctx     context.Context
+	t       tenant.Model
+	p       producer.Provider
+	chanP   channel.Processor
+	charP   character.Processor
+	portalP portal.Processor
+	occP    occupancy.Processor
+	moved   *atomic.Int32
+	stagger *staggerPlan
+	entered *stateLog
+	clk     clock.Clock
 }
 
 // NewProcessor creates a new processor implementation
 func NewProcessor(l logrus.FieldLogger, ctx context.Context) Processor {
+	return newProcessor(l, ctx)
+}
+
+func newProcessor(l logrus.FieldLogger, ctx context.Context) *ProcessorImpl {
 	return &ProcessorImpl{
-		l:     l,
-		ctx:   ctx,
-		t:     tenant.MustFromContext(ctx),
-		p:     producer.ProviderImpl(l)(ctx),
-		chanP: channel.NewProcessor(l, ctx),
-		charP: character.NewProcessor(l, ctx),
-		mp:    _map.NewProcessor(l, ctx),
+		l:       l,
+		ctx:     ctx,
+		t:       tenant.MustFromContext(ctx),
+		p:       producer.ProviderImpl(l)(ctx),
+		chanP:   channel.NewProcessor(l, ctx),
+		charP:   character.NewProcessor(l, ctx),
+		portalP: portal.NewProcessor(l, ctx),
+		occP:    occupancy.NewProcessor(l, ctx),
+		moved:   &atomic.Int32{},
+		stagger: newStaggerPlan(),
+		entered: &stateLog{},
+		clk:     clock.NewProcessor(l, ctx).Clock(),
 	}
 }
 
-func (p *ProcessorImpl) AddTenant(distinctRoutes []Model, sharedVessels []SharedVesselModel) error {
-	p.l.Debugf("Adding [%d] routes for tenant [%s].", len(distinctRoutes), p.t.Id())
-	routeMap := make(map[uuid.UUID]Model)
-	for _, route := range distinctRoutes {
-		routeMap[route.Id()] = route
-	}
-	schedules := NewScheduler(distinctRoutes, sharedVessels).ComputeSchedule()
-	for _, schedule := range schedules {
-		if route, ok := routeMap[schedule.RouteId()]; ok {
-			routeMap[route.Id()] = route.Builder().AddToSchedule(schedule).Build()
+// WithContext returns a copy of the processor which logs, traces and publishes under the given context. The tenant,
+// clock and the state of the transition being issued are shared with the original.
+func (p *ProcessorImpl) WithContext(l logrus.FieldLogger, ctx context.Context) *ProcessorImpl {
+	c := *p
+	c.l = l
+	c.ctx = ctx
+	c.p = producer.ProviderImpl(l)(ctx)
+	c.chanP = channel.NewProcessor(l, ctx)
+	c.charP = character.NewProcessor(l, ctx)
+	c.portalP = portal.NewProcessor(l, ctx)
+	c.occP = occupancy.NewProcessor(l, ctx)
+	return &c
+}
+
+// AddTenant validates and schedules the route and shared vessel definitions of the tenant. Definitions which fail
+// validation are quarantined and reported, while the rest are scheduled.
+func (p *ProcessorImpl) AddTenant(routes []Model, vessels []SharedVesselModel) error {
+	p.l.Debugf("Adding [%d] routes for tenant [%s].", len(routes), p.t.Id())
+	distinctRoutes, sharedVessels, quarantined := ValidateConfiguration(routes, vessels)
+	for _, q := range quarantined {
+		for _, fe := range q.Errors() {
+			p.l.Warnf("Quarantining %s [%s] named [%s] for tenant [%s]: [%s] %s.", q.Kind(), q.Id(), q.Name(), p.t.Id(), fe.Field(), fe.Message())
 		}
 	}
-	scheduledRoutes := make([]Model, 0)
-	for _, route := range routeMap {
-		scheduledRoutes = append(scheduledRoutes, route)
+	getQuarantineRegistry().Set(p.t, quarantined)
+
+	scheduledRoutes := ScheduleRoutes(p.clk, distinctRoutes, sharedVessels)
+	for _, route := range scheduledRoutes {
+		if len(route.Schedule()) == 0 {
+			p.l.Warnf("Route [%s] named [%s] for tenant [%s] has no trips which complete within the day.", route.Id(), route.Name(), p.t.Id())
+		}
 	}
 
 	getRouteRegistry().AddTenant(p.t, scheduledRoutes)
+	getVesselRegistry().AddTenant(p.t, sharedVessels)
+	for _, route := range scheduledRoutes {
+		p.recordState(route)
+	}
+
+	var mapIds []map2.Id
+	for _, route := range distinctRoutes {
+		mapIds = append(mapIds, route.MapIds()...)
+	}
+	p.occP.Track(mapIds...)
+	_ = p.portalP.Preload(mapIds...)
 	return nil
 }
 
@@ -92,106 +177,964 @@ func (p *ProcessorImpl) AllRoutesProvider() model.Provider[[]Model] {
 	}
 }
 
+// ByMapIdProvider returns a provider for the routes which use the map in any of the given roles, or in any role when
+// none are given
+func (p *ProcessorImpl) ByMapIdProvider(mapId map2.Id, roles ...MapRole) model.Provider[[]Model] {
+	return func() ([]Model, error) {
+		return getRouteRegistry().GetRoutesByMap(p.t, mapId, roles...), nil
+	}
+}
+
+// UpdateRoutes updates every route of the tenant which is not already being updated, and waits for the updates to
+// finish. Transitions publish their warps paced to the tenant's warp rate limit, which may outlast a tick, so the pass
+// gives up waiting after tickDeadline. A route still updating is left to finish, and is skipped by later passes until it
+// does, so a slow route holds up neither the tick nor the other routes.
 func (p *ProcessorImpl) UpdateRoutes() error {
-	return model.ForEachSlice(p.AllRoutesProvider(), p.UpdateRouteAndEmit, model.ParallelExecute())
+	start := time.Now()
+	tl, tctx, span := tracing.StartSpan(p.l, p.ctx, "tick", trace.WithAttributes(attribute.String("tenant.id", p.t.Id().String())))
+	var wg sync.WaitGroup
+	err := model.ForEachSlice(p.AllRoutesProvider(), func(route Model) error {
+		if !getInFlightRegistry().Acquire(p.t, route.Id()) {
+			tl.Debugf("Route [%s] is still being updated, skipping tick.", route.Id())
+			return nil
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer getInFlightRegistry().Release(p.t, route.Id())
+			// The route may have been committed by the update which was in flight when it was read.
+			current, ok := getRouteRegistry().GetRoute(p.t, route.Id())
+			if !ok {
+				return
+			}
+			rl, rctx, rspan := tracing.StartSpan(tl, tctx, "route.update", trace.WithAttributes(attribute.String("route.id", current.Id().String()), attribute.String("route.state", string(current.State()))))
+			err := p.WithContext(rl, rctx).UpdateRouteAndEmit(current)
+			tracing.EndSpan(rspan, err)
+		}()
+		return nil
+	})
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-p.ctx.Done():
+		err = errors.Join(err, p.ctx.Err())
+	case <-time.After(tickDeadline):
+		tl.Warnf("Route updates did not finish within [%s], leaving them to finish in the background.", tickDeadline)
+		err = errors.Join(err, ErrTickDeadlineExceeded)
+	}
+	tracing.EndSpan(span, err)
+	metrics.Tick(p.t.Id().String(), time.Since(start))
+	return err
+}
+
+// warpProducer publishes warp commands paced to the tenant's warp rate limit, so that a transition moving many
+// passengers does not burst the broker and the channels consuming the commands.
+func (p *ProcessorImpl) warpProducer() producer.Provider {
+	return producer.Paced(p.ctx, throttle.ForTenant(p.l, p.t), character2.EnvCommandTopic)(p.p)
 }
 
+// recordState publishes the committed state of the route as a metric.
+func (p *ProcessorImpl) recordState(r Model) {
+	states := make([]string, 0, len(routeStates))
+	for _, s := range routeStates {
+		states = append(states, string(s))
+	}
+	metrics.RouteState(p.t.Id().String(), r.Id().String(), string(r.State()), states)
+}
+
+// UpdateRouteAndEmit advances the route to the state dictated by the clock. The new state is only committed once all
+// side effects of the transition have been published. Failed transitions are retried with backoff on later ticks.
 func (p *ProcessorImpl) UpdateRouteAndEmit(route Model) error {
-	return message.Emit(p.p)(model.Flip(p.UpdateRoute)(route))
+	now := p.clk.Now()
+	r, changed := route.UpdateState(now)
+	if !changed && !skippedCycle(route, now) {
+		getRouteRegistry().Touch(p.t, route.Id(), now)
+		getPendingTransitionRegistry().Clear(p.t, route.Id())
+		return nil
+	}
+
+	if pt, ok := getPendingTransitionRegistry().Get(p.t, route.Id()); ok && now.Before(pt.NextAttemptAt()) {
+		return nil
+	}
+
+	// Lag is measured when the transition is detected, so publishing its side effects does not count against the schedule.
+	lag := now.Sub(r.LastTransitionAt(now))
+	tl, tctx, span := tracing.StartSpan(p.l, p.ctx, "route.transition", trace.WithAttributes(
+		attribute.String("route.id", route.Id().String()),
+		attribute.String("route.from", string(route.State())),
+		attribute.String("route.to", string(r.State())),
+	))
+	// The transition's span context travels in the headers of every command it publishes, linking them back to it.
+	tp := p.WithContext(tl, tctx)
+	tp.moved = &atomic.Int32{}
+	tp.stagger = newStaggerPlan()
+	tp.entered = &stateLog{}
+	err := message.Emit(tp.warpProducer())(func(mb *message.Buffer) error {
+		return tp.Transition(mb)(route, r, now)
+	})
+	tracing.EndSpan(span, err)
+	metrics.Transition(p.t.Id().String(), route.Id().String(), string(route.State()), string(r.State()), lag, err)
+	rec := p.recordTransition(route, r, now, int(tp.moved.Load()), err)
+	if err != nil {
+		pt := getPendingTransitionRegistry().Fail(p.t, route.Id(), route.State(), r.State(), err, now)
+		p.l.WithError(err).Warnf("Transition of route [%s] from [%s] to [%s] failed on attempt [%d], retrying at [%s].", route.Id(), route.State(), r.State(), pt.Attempts(), pt.NextAttemptAt().Format(time.RFC3339))
+		return err
+	}
+
+	// The route is committed together with the stream events of the states it entered, so that a stream snapshot is
+	// consistent with the events following it.
+	err = getStreamRegistry().Commit(p.t, func() error {
+		return getRouteRegistry().UpdateRoute(p.t, r)
+	}, StreamEventTransition, TransformStreamTransitions(r, rec, tp.entered.Entered())...)
+	if err != nil {
+		p.l.WithError(err).Errorf("Error updating route [%s].", route.Id())
+		return err
+	}
+	p.recordState(r)
+	tp.schedulePhases(r, rec, tp.stagger.Phases())
+	if pt, ok := getPendingTransitionRegistry().Get(p.t, route.Id()); ok {
+		p.l.Infof("Transition of route [%s] to [%s] succeeded after [%d] failed attempts.", r.Id(), r.State(), pt.Attempts())
+		getPendingTransitionRegistry().Clear(p.t, route.Id())
+	}
+	return nil
 }
 
-func (p *ProcessorImpl) UpdateRoute(mb *message.Buffer) func(route Model) error {
-	return func(route Model) error {
-		now := time.Now()
-		r, changed := route.UpdateState(now)
-		if changed {
-			err := getRouteRegistry().UpdateRoute(p.t, r)
+// recordTransition adds a transition attempt to the route's history. Warps of staggered channels are published after
+// the transition commits, and are added to the record as each channel's phase is published.
+func (p *ProcessorImpl) recordTransition(from Model, to Model, now time.Time, moved int, err error) TransitionRecordModel {
+	m := TransitionRecordModel{
+		id:               uuid.New(),
+		routeId:          from.Id(),
+		fromState:        from.State(),
+		toState:          to.State(),
+		scheduledAt:      to.LastTransitionAt(now),
+		occurredAt:       now,
+		tripOccurrenceId: lastTripOccurrenceId(to, now),
+		passengersMoved:  moved,
+	}
+	if err != nil {
+		m.err = err.Error()
+	}
+	return getTransitionHistoryRegistry().Add(p.t, m)
+}
+
+// HistoryProvider returns a provider for the recorded transitions of a route which occurred within [since, until).
+// Zero bounds are open.
+func (p *ProcessorImpl) HistoryProvider(routeId uuid.UUID, since time.Time, until time.Time) model.Provider[[]TransitionRecordModel] {
+	return func() ([]TransitionRecordModel, error) {
+		if _, ok := getRouteRegistry().GetRoute(p.t, routeId); !ok {
+			return nil, errors.New("route not found")
+		}
+		return getTransitionHistoryRegistry().Get(p.t, routeId, since, until), nil
+	}
+}
+
+// ScheduleProvider returns a provider for the routes of the tenant, each carrying the trips which open boarding within
+// [from, to), dated to the days they run on.
+func (p *ProcessorImpl) ScheduleProvider(from time.Time, to time.Time) model.Provider[[]Model] {
+	return model.SliceMap(datedSchedule(from, to))(p.AllRoutesProvider())()
+}
+
+// RouteScheduleProvider returns a provider for the route, carrying the trips which open boarding within [from, to),
+// dated to the days they run on.
+func (p *ProcessorImpl) RouteScheduleProvider(routeId uuid.UUID, from time.Time, to time.Time) model.Provider[[]Model] {
+	return model.Map(func(m Model) ([]Model, error) {
+		dm, err := datedSchedule(from, to)(m)
+		return []Model{dm}, err
+	})(p.ByIdProvider(routeId))
+}
+
+// datedSchedule replaces the daily schedule of a route with its trips within [from, to).
+func datedSchedule(from time.Time, to time.Time) model.Transformer[Model, Model] {
+	return func(m Model) (Model, error) {
+		return m.Builder().SetSchedule(m.TripsBetween(from, to)).Build(), nil
+	}
+}
+
+// QuarantineProvider returns a provider for the route and shared vessel definitions of the tenant which failed
+// validation when last loaded.
+func (p *ProcessorImpl) QuarantineProvider() model.Provider[[]QuarantineModel] {
+	return func() ([]QuarantineModel, error) {
+		return getQuarantineRegistry().Get(p.t), nil
+	}
+}
+
+// NetworkProvider returns a provider for the transport network formed by the routes and shared vessels of the tenant.
+func (p *ProcessorImpl) NetworkProvider() model.Provider[NetworkModel] {
+	return model.Map(func(routes []Model) (NetworkModel, error) {
+		return BuildNetwork(routes, getVesselRegistry().GetVessels(p.t)), nil
+	})(p.AllRoutesProvider())
+}
+
+// JourneyProvider returns a provider for itineraries from one map to another across the routes of the tenant, for a
+// traveller ready to depart at departAfter, ordered by earliest arrival.
+func (p *ProcessorImpl) JourneyProvider(fromMapId map2.Id, toMapId map2.Id, departAfter time.Time, limit int) model.Provider[[]JourneyModel] {
+	return model.Map(func(routes []Model) ([]JourneyModel, error) {
+		return PlanJourneys(routes, fromMapId, toMapId, departAfter, limit), nil
+	})(p.AllRoutesProvider())
+}
+
+// SubscribeStream subscribes to the live route events of the tenant. Events after lastEventId are replayed when
+// resuming is requested and they are still retained. Otherwise, the replay starts with a snapshot of every route. The
+// returned function unsubscribes.
+func (p *ProcessorImpl) SubscribeStream(lastEventId uint64, resume bool) (<-chan StreamEventModel, []StreamEventModel, func()) {
+	ch, replay, _, _, unsubscribe := getStreamRegistry().Subscribe(p.t, lastEventId, resume, func() interface{} {
+		routes, err := model.SliceMap(TransformStreamRouteState(p.clk.Now()))(p.AllRoutesProvider())()()
+		if err != nil {
+			p.l.WithError(err).Warnf("Unable to build route snapshot for stream.")
+			routes = make([]StreamRouteStateRestModel, 0)
+		}
+		return StreamSnapshotRestModel{Routes: routes}
+	})
+	return ch, replay, unsubscribe
+}
+
+// Timezone returns the zone the tenant's schedules are published in.
+func (p *ProcessorImpl) Timezone() *time.Location {
+	return timezoneFor(p.l, p.t.Id())
+}
+
+// Transition buffers the side effects of the route moving from its committed state to its new state. When
+// intermediate states were skipped, their side effects are replayed in order.
+func (p *ProcessorImpl) Transition(mb *message.Buffer) func(from Model, to Model, now time.Time) error {
+	return func(from Model, to Model, now time.Time) error {
+		tripOccurrenceId := lastTripOccurrenceId(to, now)
+
+		previous, path := replayPath(from, to, now)
+		if len(path) > 1 {
+			p.l.Warnf("Route [%s] skipped transitions moving from [%s] to [%s], replaying %v.", to.Id(), from.State(), to.State(), path)
+		}
+
+		for i, state := range path {
+			arrivesLater := false
+			for j := i + 1; j < len(path); j++ {
+				if path[j-1] == InTransit {
+					arrivesLater = true
+				}
+			}
+			err := p.enterState(mb)(to, tripOccurrenceId)(previous, state, arrivesLater)
 			if err != nil {
-				p.l.WithError(err).Errorf("Error updating route [%s].", route.Id())
+				return err
 			}
-			if r.State() == AwaitingReturn {
+			previous = state
+		}
+		return nil
+	}
+}
+
+// enterState buffers the side effects of the route entering a single state. When the trip also arrives later in the
+// same transition, departing passengers are carried straight to the destination, as the en-route map has not been
+// repopulated by the time the arrival is replayed.
+func (p *ProcessorImpl) enterState(mb *message.Buffer) func(r Model, tripOccurrenceId string) func(previous RouteState, state RouteState, arrivesLater bool) error {
+	return func(r Model, tripOccurrenceId string) func(previous RouteState, state RouteState, arrivesLater bool) error {
+		return func(previous RouteState, state RouteState, arrivesLater bool) error {
+			var err error
+			if state == AwaitingReturn || (state == OutOfService && previous == InTransit) {
 				p.l.Infof("Transport for route [%s] has arrived at [%d].", r.Id(), r.DestinationMapId())
+				var ms []movement
 				for _, enRouteMapId := range r.EnRouteMapIds() {
-					err = model.ForEachSlice(model.FixedProvider(p.chanP.GetAll()), func(c channel2.Model) error {
-						ff := field.NewBuilder(c.WorldId(), c.Id(), enRouteMapId).Build()
-						tf := field.NewBuilder(c.WorldId(), c.Id(), r.DestinationMapId()).Build()
-						return p.warpTo(mb)(ff, tf)
-					}, model.ParallelExecute())
-					if err != nil {
-						p.l.WithError(err).Errorf("Error warping characters from enroute map [%d] to destination map [%d].", enRouteMapId, r.DestinationMapId())
-						return err
-					}
+					ms = append(ms, p.movements(r, enRouteMapId, r.DestinationMapId())...)
+				}
+				err = p.warp(mb)(r, tripOccurrenceId, LegArrival)(ms)
+				if err != nil {
+					p.l.WithError(err).Errorf("Error warping characters from enroute maps to destination map [%d].", r.DestinationMapId())
+					return err
 				}
 			}
-			if r.State() == OpenEntry {
-				err = mb.Put(transport.EnvEventTopicStatus, ArrivedStatusEventProvider(r.Id(), r.ObservationMapId()))
+			if state == OpenEntry {
+				err = p.announce(mb)(r, transport.EventStatusArrived)
 				if err != nil {
 					p.l.WithError(err).Errorf("Error sending status event for route [%s].", r.Id())
 					return err
 				}
-			} else if r.State() == LockedEntry {
+			} else if state == LockedEntry {
 				p.l.Infof("Transport for route [%s] has locked doors at [%d].", r.Id(), r.StagingMapId())
-			} else if r.State() == InTransit {
+			} else if state == InTransit {
 				p.l.Infof("Transport for route [%s] has departed [%d].", r.Id(), r.StagingMapId())
-				err = model.ForEachSlice(model.FixedProvider(p.chanP.GetAll()), func(c channel2.Model) error {
-					ff := field.NewBuilder(c.WorldId(), c.Id(), r.StagingMapId()).Build()
-					tf := field.NewBuilder(c.WorldId(), c.Id(), r.EnRouteMapIds()[0]).Build()
-					return p.warpTo(mb)(ff, tf)
-				}, model.ParallelExecute())
+				toMapId := r.EnRouteMapIds()[0]
+				if arrivesLater {
+					toMapId = r.DestinationMapId()
+				}
+				err = p.warp(mb)(r, tripOccurrenceId, LegDeparture)(p.movements(r, r.StagingMapId(), toMapId))
 				if err != nil {
-					p.l.WithError(err).Errorf("Error warping characters from staging map [%d] to enroute map.", r.StagingMapId())
+					p.l.WithError(err).Errorf("Error warping characters from staging map [%d] to map [%d].", r.StagingMapId(), toMapId)
 					return err
 				}
-				err = mb.Put(transport.EnvEventTopicStatus, DepartedStatusEventProvider(r.Id(), r.ObservationMapId()))
+				err = p.announce(mb)(r, transport.EventStatusDeparted)
 				if err != nil {
 					p.l.WithError(err).Errorf("Error sending status event for route [%s].", r.Id())
 					return err
 				}
 			}
+			// The state is streamed once its side effects are published, as its Kafka status events are.
+			mb.OnEmit(func(err error) {
+				if err == nil {
+					p.entered.add(previous, state)
+				}
+			})
+			return nil
 		}
-		return nil
 	}
 }
 
-func (p *ProcessorImpl) warpTo(mb *message.Buffer) func(fromField field.Model, toField field.Model) error {
-	return func(ff field.Model, tf field.Model) error {
-		cp := p.mp.CharacterIdsInMapProvider(ff.WorldId(), ff.ChannelId(), ff.MapId())
-		return model.ForEachSlice(cp, func(characterId uint32) error {
-			p.l.Infof("Warping character [%d] from map [%d] to map [%d].", characterId, ff.MapId(), tf.MapId())
-			return p.charP.WarpRandom(mb)(characterId)(tf.Id())
+// PendingTransitionsProvider returns a provider for the transitions of the tenant which are awaiting a retry
+func (p *ProcessorImpl) PendingTransitionsProvider() model.Provider[[]PendingTransitionModel] {
+	return func() ([]PendingTransitionModel, error) {
+		return getPendingTransitionRegistry().GetAll(p.t), nil
+	}
+}
+
+// snapshot describes the current state of the route to a single channel. A staggered channel is described by the
+// state it was last sent, and the next transition is given as the channel will see it.
+func (p *ProcessorImpl) snapshot(mb *message.Buffer) func(worldId world.Id, channelId channel2.Id) func(route Model) error {
+	return func(worldId world.Id, channelId channel2.Id) func(route Model) error {
+		return func(route Model) error {
+			now := p.clk.Now()
+			view, at := p.channelView(route, worldId, channelId, now)
+			var next time.Time
+			var remaining time.Duration
+			if n, ok := view.NextTransitionAt(at); ok {
+				remaining = n.Sub(at)
+				next = now.Add(remaining)
+			}
+			return mb.Put(transport.EnvEventTopicStatus, SnapshotStatusEventProvider(route.Id(), worldId, channelId, route.StartMapId(), route.DestinationMapId(), route.ObservationMapId(), view.State(), next, remaining))
+		}
+	}
+}
+
+// SendChannelSnapshots describes the current state of every route to a channel, such as one which has just started.
+func (p *ProcessorImpl) SendChannelSnapshots(mb *message.Buffer) func(worldId world.Id, channelId channel2.Id) error {
+	return func(worldId world.Id, channelId channel2.Id) error {
+		p.l.Debugf("Sending route status snapshots to world [%d] channel [%d].", worldId, channelId)
+		enabled := model.FilteredProvider(p.AllRoutesProvider(), model.Filters(func(m Model) bool {
+			return m.EnabledIn(worldId, channelId)
+		}))
+		return model.ForEachSlice(enabled, p.snapshot(mb)(worldId, channelId))
+	}
+}
+
+func (p *ProcessorImpl) SendChannelSnapshotsAndEmit(worldId world.Id, channelId channel2.Id) error {
+	return message.Emit(p.p)(func(mb *message.Buffer) error {
+		return p.SendChannelSnapshots(mb)(worldId, channelId)
+	})
+}
+
+// SendObservationSnapshots describes the current state of every route observed from the map to the channel of the
+// field, such as when a character enters it.
+func (p *ProcessorImpl) SendObservationSnapshots(mb *message.Buffer) func(f field.Model) error {
+	return func(f field.Model) error {
+		observed := model.FilteredProvider(p.ByMapIdProvider(f.MapId(), MapRoleObservation), model.Filters(func(m Model) bool {
+			return m.EnabledIn(f.WorldId(), f.ChannelId())
+		}))
+		return model.ForEachSlice(observed, p.snapshot(mb)(f.WorldId(), f.ChannelId()))
+	}
+}
+
+func (p *ProcessorImpl) SendObservationSnapshotsAndEmit(f field.Model) error {
+	return message.Emit(p.p)(func(mb *message.Buffer) error {
+		return p.SendObservationSnapshots(mb)(f)
+	})
+}
+
+// fields returns the maps on every registered channel the route is enabled in.
+func (p *ProcessorImpl) fields(r Model, mapIds ...map2.Id) []field.Model {
+	var fs []field.Model
+	for _, c := range p.chanP.GetAll() {
+		if !r.EnabledIn(c.WorldId(), c.Id()) {
+			continue
+		}
+		for _, mapId := range mapIds {
+			fs = append(fs, field.NewBuilder(c.WorldId(), c.Id(), mapId).Build())
+		}
+	}
+	return fs
+}
+
+// OccupancyProvider returns a provider for the characters in each of the route's maps, on every registered channel the
+// route is enabled in.
+func (p *ProcessorImpl) OccupancyProvider(routeId uuid.UUID) model.Provider[[]occupancy.Model] {
+	return func() ([]occupancy.Model, error) {
+		route, err := p.ByIdProvider(routeId)()
+		if err != nil {
+			return nil, err
+		}
+		return p.occP.ByFieldsProvider(p.fields(route, route.MapIds()...))()
+	}
+}
+
+// ReconcileOccupancy refreshes the occupancy of every route map of the tenant from the MAPS service, correcting any
+// drift from missed character events.
+func (p *ProcessorImpl) ReconcileOccupancy() error {
+	routes, err := p.AllRoutesProvider()()
+	if err != nil {
+		return err
+	}
+	var fs []field.Model
+	for _, route := range routes {
+		fs = append(fs, p.fields(route, route.MapIds()...)...)
+	}
+	err = p.occP.Reconcile(fs)
+	if err != nil {
+		p.l.WithError(err).Errorf("Unable to reconcile occupancy for tenant [%s].", p.t.Id())
+	}
+	return err
+}
+
+// movements pairs the from and to maps on every registered channel the route is enabled in.
+func (p *ProcessorImpl) movements(r Model, fromMapId map2.Id, toMapId map2.Id) []movement {
+	var ms []movement
+	for _, c := range p.chanP.GetAll() {
+		if !r.EnabledIn(c.WorldId(), c.Id()) {
+			continue
+		}
+		ff := field.NewBuilder(c.WorldId(), c.Id(), fromMapId).Build()
+		tf := field.NewBuilder(c.WorldId(), c.Id(), toMapId).Build()
+		ms = append(ms, movement{from: ff, to: tf})
+	}
+	return ms
+}
+
+// movementOn pairs the from and to maps on a single channel.
+func movementOn(c channel2.Model, fromMapId map2.Id, toMapId map2.Id) movement {
+	return movement{
+		from: field.NewBuilder(c.WorldId(), c.Id(), fromMapId).Build(),
+		to:   field.NewBuilder(c.WorldId(), c.Id(), toMapId).Build(),
+	}
+}
+
+// forEachPassenger runs the operator for every character found in the from field of the movements. Occupancy is
+// queried in one batch, as are the positions of passengers the route lands where they stand. The operators run on the
+// tenant's bounded worker pool.
+func (p *ProcessorImpl) forEachPassenger(r Model, ms []movement, operator func(ps passenger) error) error {
+	var fields []field.Model
+	for _, m := range ms {
+		fields = append(fields, m.from)
+	}
+	occupants, err := p.occP.CharacterIdsInFieldsProvider(fields)()
+	if err != nil {
+		return err
+	}
+
+	var ps []passenger
+	for _, m := range ms {
+		for _, characterId := range occupants[m.from.Id()] {
+			ps = append(ps, passenger{characterId: characterId, movement: m})
+		}
+	}
+	return throttle.ForEach(throttle.ForTenant(p.l, p.t), p.locate(r, ps), operator)
+}
+
+// locate resolves, in one batch, the positions of the passengers the route warps with WarpPreservePosition. Should the
+// batch fail, those passengers land on a random spawn point rather than being looked up one at a time.
+func (p *ProcessorImpl) locate(r Model, ps []passenger) []passenger {
+	var ids []uint32
+	for _, ps := range ps {
+		if r.WarpStrategyFor(ps.movement.to.MapId()).Type() == WarpPreservePosition {
+			ids = append(ids, ps.characterId)
+		}
+	}
+	if len(ids) == 0 {
+		return ps
+	}
+
+	positions := make(map[uint32]character.Model)
+	cs, err := p.charP.ByIdsProvider(ids)()
+	if err != nil {
+		p.l.WithError(err).Warnf("Unable to retrieve positions of [%d] passengers of route [%s].", len(ids), r.Id())
+	}
+	for _, c := range cs {
+		positions[c.Id()] = c
+	}
+	for i := range ps {
+		if r.WarpStrategyFor(ps[i].movement.to.MapId()).Type() != WarpPreservePosition {
+			continue
+		}
+		if c, ok := positions[ps[i].characterId]; ok {
+			ps[i].position = model.FixedProvider(c)
+		} else if err != nil {
+			ps[i].position = model.ErrorProvider[character.Model](err)
+		} else {
+			ps[i].position = model.ErrorProvider[character.Model](errors.New("character not found"))
+		}
+	}
+	return ps
+}
+
+// warpTo buffers a warp of the character to the target field. The target portal is chosen by the route's warp strategy
+// for the target map. Warps are paced to the tenant's warp rate limit when published, see warpProducer.
+func (p *ProcessorImpl) warpTo(mb *message.Buffer) func(r Model, key string, ps passenger) error {
+	return func(r Model, key string, ps passenger) error {
+		characterId := ps.characterId
+		tf := ps.movement.to
+		wl, wctx, span := tracing.StartSpan(p.l, p.ctx, "warpTo", trace.WithAttributes(
+			attribute.Int64("character.id", int64(characterId)),
+			attribute.String("field.id", string(tf.Id())),
+			attribute.String("idempotency.key", key),
+		))
+		wp := p.WithContext(wl, wctx)
+		err := wp.charP.IdempotentWarpToPortal(mb)(key, characterId, tf.Id(), wp.portalIdProvider(r.WarpStrategyFor(tf.MapId()), ps))
+		if err != nil {
+			tracing.EndSpan(span, err)
+			return err
+		}
+		// The span covers the warp until it is published with the rest of the buffer.
+		mb.OnEmit(func(err error) {
+			tracing.EndSpan(span, err)
 		})
+		return nil
+	}
+}
+
+// portalIdProvider resolves the target portal of a warp strategy, falling back to a random spawn point when the
+// configured portal or the character's position cannot be resolved. The position of a passenger not already located
+// is requested on its own.
+func (p *ProcessorImpl) portalIdProvider(s WarpStrategy, ps passenger) model.Provider[uint32] {
+	characterId := ps.characterId
+	mapId := ps.movement.to.MapId()
+	random := p.portalP.RandomSpawnPointIdProvider(mapId)
+	var pp model.Provider[uint32]
+	switch s.Type() {
+	case WarpFixedPortal:
+		return model.FixedProvider(s.PortalId())
+	case WarpNamedPortal:
+		pp = p.portalP.ByNameIdProvider(mapId, s.PortalName())
+	case WarpPreservePosition:
+		position := ps.position
+		if position == nil {
+			position = p.charP.ByIdProvider(characterId)
+		}
+		pp = func() (uint32, error) {
+			c, err := position()
+			if err != nil {
+				return 0, err
+			}
+			return p.portalP.ClosestSpawnPointIdProvider(mapId, c.X(), c.Y())()
+		}
+	default:
+		return random
+	}
+	return func() (uint32, error) {
+		id, err := pp()
+		if err != nil {
+			p.l.WithError(err).Warnf("Unable to resolve [%s] warp target in map [%d] for character [%d], using a random spawn point.", s.Type(), mapId, characterId)
+			return random()
+		}
+		return id, nil
+	}
+}
+
+// warp buffers the warps of the movements on channels without an offset, and collects those of staggered channels
+// into the transition's stagger plan.
+func (p *ProcessorImpl) warp(mb *message.Buffer) func(r Model, tripOccurrenceId string, leg Leg) func(ms []movement) error {
+	return func(r Model, tripOccurrenceId string, leg Leg) func(ms []movement) error {
+		return func(ms []movement) error {
+			offsets := p.channelOffsets(r)
+			var immediate []movement
+			for _, m := range ms {
+				c := channel2.NewModel(m.from.WorldId(), m.from.ChannelId())
+				if offset := offsets[c]; offset > 0 {
+					p.stagger.addWarp(c, offset, tripOccurrenceId, leg, m)
+				} else {
+					immediate = append(immediate, m)
+				}
+			}
+			return p.warpNow(mb)(r, tripOccurrenceId, leg)(immediate)
+		}
+	}
+}
+
+// announce buffers a status event of the route. Routes which stagger their channels address an event to each channel,
+// and collect those of staggered channels into the transition's stagger plan.
+func (p *ProcessorImpl) announce(mb *message.Buffer) func(r Model, eventType string) error {
+	return func(r Model, eventType string) error {
+		if r.ChannelStagger() <= 0 {
+			if eventType == transport.EventStatusArrived {
+				return mb.Put(transport.EnvEventTopicStatus, ArrivedStatusEventProvider(r.Id(), r.ObservationMapId()))
+			}
+			return mb.Put(transport.EnvEventTopicStatus, DepartedStatusEventProvider(r.Id(), r.ObservationMapId()))
+		}
+		for c, offset := range p.channelOffsets(r) {
+			if offset > 0 {
+				p.stagger.addEvent(c, offset, eventType)
+				continue
+			}
+			if err := mb.Put(transport.EnvEventTopicStatus, channelStatusEventProvider(r, c, eventType)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func channelStatusEventProvider(r Model, c channel2.Model, eventType string) model.Provider[[]kafka.Message] {
+	if eventType == transport.EventStatusArrived {
+		return ChannelArrivedStatusEventProvider(r.Id(), c.WorldId(), c.Id(), r.ObservationMapId())
+	}
+	return ChannelDepartedStatusEventProvider(r.Id(), c.WorldId(), c.Id(), r.ObservationMapId())
+}
+
+// channelOffsets returns the phase offset of each registered channel the route is enabled in.
+func (p *ProcessorImpl) channelOffsets(r Model) map[channel2.Model]time.Duration {
+	return channelOffsets(r, p.chanP.GetAll())
+}
+
+// schedulePhases issues each staggered channel's share of a committed transition once its offset has elapsed. Each
+// phase is published on its own, and the passengers it moves are added to the transition's record. Failures are left
+// to the recovery sweep, which judges each channel by the state it was last sent.
+func (p *ProcessorImpl) schedulePhases(r Model, rec TransitionRecordModel, phases []channelPhase) {
+	for _, cp := range phases {
+		go func(cp channelPhase) {
+			select {
+			case <-p.ctx.Done():
+				return
+			case <-p.clk.After(cp.Offset()):
+			}
+			pp := p.WithContext(p.l, p.ctx)
+			pp.moved = &atomic.Int32{}
+			err := message.Emit(pp.warpProducer())(func(mb *message.Buffer) error {
+				return pp.issuePhase(mb)(r, cp)
+			})
+			if moved := int(pp.moved.Load()); moved > 0 {
+				getTransitionHistoryRegistry().AddPassengersMoved(p.t, r.Id(), rec.Id(), moved)
+			}
+			if err != nil {
+				p.l.WithError(err).Errorf("Error issuing staggered transition of route [%s] to world [%d] channel [%d].", r.Id(), cp.Channel().WorldId(), cp.Channel().Id())
+			}
+		}(cp)
+	}
+}
+
+// issuePhase buffers the side effects of a staggered channel's phase, in the order the transition produced them.
+func (p *ProcessorImpl) issuePhase(mb *message.Buffer) func(r Model, cp channelPhase) error {
+	return func(r Model, cp channelPhase) error {
+		for _, step := range cp.steps {
+			var err error
+			if step.event != "" {
+				err = mb.Put(transport.EnvEventTopicStatus, channelStatusEventProvider(r, cp.Channel(), step.event))
+			} else {
+				err = p.warpNow(mb)(r, step.tripOccurrenceId, step.leg)(step.movements)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func (p *ProcessorImpl) warpNow(mb *message.Buffer) func(r Model, tripOccurrenceId string, leg Leg) func(ms []movement) error {
+	return func(r Model, tripOccurrenceId string, leg Leg) func(ms []movement) error {
+		return func(ms []movement) error {
+			return p.forEachPassenger(r, ms, func(ps passenger) error {
+				characterId, m := ps.characterId, ps.movement
+				key := ""
+				if tripOccurrenceId != "" {
+					key = WarpKey(tripOccurrenceId, characterId, leg)
+				}
+				p.l.Infof("Warping character [%d] from map [%d] to map [%d] for [%s] leg of trip [%s].", characterId, m.from.MapId(), m.to.MapId(), leg, tripOccurrenceId)
+				err := p.warpTo(mb)(r, key, ps)
+				if err != nil {
+					metrics.Warp(p.t.Id().String(), string(leg), err)
+					return err
+				}
+				// A passenger has only moved once the warp is published.
+				mb.OnEmit(func(err error) {
+					metrics.Warp(p.t.Id().String(), string(leg), err)
+					if err == nil {
+						p.moved.Add(1)
+					}
+				})
+				return nil
+			})
+		}
+	}
+}
+
+// RecoverStrandedPassengers warps characters out of route maps which the committed route state says should be empty.
+// Characters left in the staging map never departed and are returned to the start map. Characters left in an en-route
+// map belong to a trip which has arrived and are carried to the destination map. A staggered channel is judged by the
+// state it was last sent, rather than the committed state.
+func (p *ProcessorImpl) RecoverStrandedPassengers(mb *message.Buffer) func(route Model) error {
+	return func(route Model) error {
+		now := p.clk.Now()
+		type sweep struct {
+			route     Model
+			movements []movement
+		}
+		var sweeps []*sweep
+		byState := make(map[RouteState]*sweep)
+		for _, c := range p.chanP.GetAll() {
+			if !route.EnabledIn(c.WorldId(), c.Id()) {
+				continue
+			}
+			view, ok := p.recoverable(route, c.WorldId(), c.Id(), now)
+			if !ok {
+				continue
+			}
+			sw, ok := byState[view.State()]
+			if !ok {
+				sw = &sweep{route: view}
+				byState[view.State()] = sw
+				sweeps = append(sweeps, sw)
+			}
+			if view.State() != OpenEntry && view.State() != LockedEntry {
+				sw.movements = append(sw.movements, movementOn(c, view.StagingMapId(), view.StartMapId()))
+			}
+			if view.State() != InTransit {
+				for _, enRouteMapId := range view.EnRouteMapIds() {
+					sw.movements = append(sw.movements, movementOn(c, enRouteMapId, view.DestinationMapId()))
+				}
+			}
+		}
+
+		for _, sw := range sweeps {
+			err := p.recoverPassengers(mb)(sw.route)(sw.movements)
+			if err != nil {
+				p.l.WithError(err).Errorf("Error recovering characters stranded on route [%s].", route.Id())
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// recoverPassengers buffers the recovery of every passenger stranded in the from fields of the movements. A passenger
+// whose warp cannot be buffered is left for the next sweep, rather than holding back the recovery of the others.
+func (p *ProcessorImpl) recoverPassengers(mb *message.Buffer) func(route Model) func(ms []movement) error {
+	return func(route Model) func(ms []movement) error {
+		return func(ms []movement) error {
+			return p.forEachPassenger(route, ms, func(ps passenger) error {
+				err := p.recoverPassenger(mb)(route)(ps)
+				if err != nil {
+					p.l.WithError(err).Warnf("Unable to recover character [%d] stranded in map [%d] on route [%s], retrying next sweep.", ps.characterId, ps.movement.from.MapId(), route.Id())
+				}
+				return nil
+			})
+		}
+	}
+}
+
+// recoverPassenger buffers the warp of a stranded passenger to the map the route state says they belong in. Recovery
+// warps carry no idempotency key: a character is only recovered while found in the stranded map, so a repeated recovery
+// is already harmless, and a character stranded again on the same trip occurrence must be moved again.
+func (p *ProcessorImpl) recoverPassenger(mb *message.Buffer) func(route Model) func(ps passenger) error {
+	return func(route Model) func(ps passenger) error {
+		return func(ps passenger) error {
+			characterId, m := ps.characterId, ps.movement
+			p.l.Infof("Recovering character [%d] stranded in map [%d] while route [%s] is [%s], warping to map [%d].", characterId, m.from.MapId(), route.Id(), route.State(), m.to.MapId())
+			err := p.warpTo(mb)(route, "", ps)
+			metrics.Warp(p.t.Id().String(), string(LegRecovery), err)
+			if err != nil {
+				return err
+			}
+			return mb.Put(transport.EnvEventTopicPassenger, RecoveredPassengerEventProvider(route.Id(), m.from.WorldId(), m.from.ChannelId(), characterId, m.from.MapId(), m.to.MapId(), route.State()))
+		}
+	}
+}
+
+// channelView returns the route as last sent to the channel, along with the time it was evaluated at. A staggered
+// channel trails the committed state by its offset.
+func (p *ProcessorImpl) channelView(route Model, worldId world.Id, channelId channel2.Id, now time.Time) (Model, time.Time) {
+	if offset := p.channelOffsets(route)[channel2.NewModel(worldId, channelId)]; offset > 0 {
+		at := now.Add(-offset)
+		view, _ := route.UpdateState(at)
+		return view, at
+	}
+	return route, now
+}
+
+// recoverable reports whether the route's state, as last sent to the channel, can be trusted to judge where its
+// passengers in the channel belong. Routes with a transition outstanding, or which transitioned within the grace period
+// of the channel's offset, are left alone. The route is returned as the channel sees it.
+func (p *ProcessorImpl) recoverable(route Model, worldId world.Id, channelId channel2.Id, now time.Time) (Model, bool) {
+	if _, changed := route.UpdateState(now); changed {
+		p.l.Debugf("Route [%s] has a transition outstanding, deferring recovery.", route.Id())
+		return route, false
+	}
+	view, at := p.channelView(route, worldId, channelId, now)
+	if at.Sub(view.LastTransitionAt(at)) < recoveryGracePeriod {
+		p.l.Debugf("Route [%s] transitioned recently in world [%d] channel [%d], deferring recovery.", route.Id(), worldId, channelId)
+		return view, false
+	}
+	return view, true
+}
+
+func lastTripOccurrenceId(route Model, now time.Time) string {
+	if trip, departedAt, ok := route.LastDeparture(now); ok {
+		return TripOccurrenceId(trip.TripId(), departedAt)
 	}
+	return ""
+}
+
+// RecoverStrandedPassengersAndEmit runs the recovery sweep over every route of the tenant.
+func (p *ProcessorImpl) RecoverStrandedPassengersAndEmit() error {
+	return model.ForEachSlice(p.AllRoutesProvider(), func(route Model) error {
+		return message.Emit(p.warpProducer())(model.Flip(p.RecoverStrandedPassengers)(route))
+	}, model.ParallelExecute())
 }
 
-func (p *ProcessorImpl) WarpToRouteStartMapOnLogout(mb *message.Buffer) func(characterId uint32, f field.Model) error {
+// RelocateOnLogout applies the logout policy of the route, for the route's current state, to a character who logged
+// out in one of its staging or en-route maps. The decision is published as a passenger event.
+func (p *ProcessorImpl) RelocateOnLogout(mb *message.Buffer) func(characterId uint32, f field.Model) error {
 	return func(characterId uint32, f field.Model) error {
-		// Get all routes for the tenant
-		routes, err := p.AllRoutesProvider()()
+		routes, err := p.ByMapIdProvider(f.MapId(), passengerMapRoles...)()
 		if err != nil {
 			p.l.WithError(err).Error("Failed to get routes for tenant")
 			return err
 		}
 
 		for _, route := range routes {
-			var mapIds []map2.Id
-			mapIds = append(mapIds, route.StagingMapId())
-			mapIds = append(mapIds, route.EnRouteMapIds()...)
+			if !route.EnabledIn(f.WorldId(), f.ChannelId()) {
+				continue
+			}
+
+			policy := route.LogoutPolicyFor(route.State())
+			var targetMapId map2.Id
+			switch policy {
+			case LogoutStay:
+				p.l.Debugf("Character [%d] logged out in map [%d] for route [%s], leaving in place.", characterId, f.MapId(), route.Id())
+			case LogoutPendingRelocation:
+				p.l.Debugf("Character [%d] logged out in map [%d] for route [%s], relocating at next login.", characterId, f.MapId(), route.Id())
+				getRelocationRegistry().Add(p.t, NewPendingRelocationModel(characterId, route.Id(), f.WorldId(), f.MapId(), route.State(), p.clk.Now()))
+			case LogoutSendToDestination:
+				targetMapId = route.DestinationMapId()
+			default:
+				targetMapId = route.StartMapId()
+			}
 
-			for _, routeMapId := range mapIds {
-				if routeMapId != f.MapId() {
-					continue
+			if targetMapId != 0 {
+				p.l.Debugf("Character [%d] logged out in map [%d] for route [%s] while [%s], warping to map [%d].", characterId, f.MapId(), route.Id(), route.State(), targetMapId)
+				tf := field.NewBuilder(f.WorldId(), f.ChannelId(), targetMapId).Build()
+				err = p.charP.WarpToPortal(mb)(characterId, tf.Id(), p.portalIdProvider(route.WarpStrategyFor(targetMapId), passenger{characterId: characterId, movement: movement{from: f, to: tf}}))
+				metrics.Warp(p.t.Id().String(), "logout", err)
+				if err != nil {
+					return err
 				}
-				p.l.Debugf("Character [%d] logged out in map [%d] for route [%s], warping to start map [%d]", characterId, f.MapId(), route.Id(), route.StartMapId())
-				tf := field.NewBuilder(f.WorldId(), f.ChannelId(), route.StartMapId()).Build()
-				return p.charP.WarpRandom(mb)(characterId)(tf.Id())
 			}
+			return mb.Put(transport.EnvEventTopicPassenger, LogoutDecisionPassengerEventProvider(route.Id(), f.WorldId(), f.ChannelId(), characterId, f.MapId(), route.State(), policy, targetMapId))
 		}
 		return nil
 	}
 }
 
-func (p *ProcessorImpl) WarpToRouteStartMapOnLogoutAndEmit(characterId uint32, f field.Model) error {
-	return message.Emit(p.p)(func(mb *message.Buffer) error {
-		return p.WarpToRouteStartMapOnLogout(mb)(characterId, f)
+func (p *ProcessorImpl) RelocateOnLogoutAndEmit(characterId uint32, f field.Model) error {
+	return message.Emit(p.warpProducer())(func(mb *message.Buffer) error {
+		return p.RelocateOnLogout(mb)(characterId, f)
+	})
+}
+
+// RelocateOnLogin moves a character who logged in to a staging or en-route map which the route's current state no
+// longer justifies. A relocation deferred at logout names the route, and sends the character to wherever its state
+// says they belong. Otherwise, the route's logout policy for its current state chooses where they are sent. The
+// pending relocation is only consumed once the character is warped, or found to no longer need relocating.
+func (p *ProcessorImpl) RelocateOnLogin(mb *message.Buffer) func(characterId uint32, f field.Model) error {
+	return func(characterId uint32, f field.Model) error {
+		now := p.clk.Now()
+		pr, pending := getRelocationRegistry().Get(p.t, characterId, now)
+		var route Model
+		if pending {
+			var err error
+			route, err = p.ByIdProvider(pr.RouteId())()
+			if err != nil {
+				p.l.WithError(err).Errorf("Unable to locate route [%s] to relocate character [%d].", pr.RouteId(), characterId)
+				return err
+			}
+		} else {
+			routes, err := p.aboardProvider(f)()
+			if err != nil {
+				return err
+			}
+			if len(routes) == 0 {
+				return nil
+			}
+			route = routes[0]
+		}
+
+		view, ok := p.recoverable(route, f.WorldId(), f.ChannelId(), now)
+		if !ok {
+			return nil
+		}
+		targetMapId, stranded := view.LoginTarget(f.MapId())
+		if pending {
+			targetMapId, stranded = view.StrandedTarget(f.MapId())
+		}
+		if !stranded {
+			getRelocationRegistry().Take(p.t, characterId, now)
+			p.l.Debugf("Character [%d] logged in to map [%d] while route [%s] is [%s], no relocation needed.", characterId, f.MapId(), route.Id(), view.State())
+			return nil
+		}
+		err := p.relocate(mb)(view, characterId, f, targetMapId)
+		if err != nil {
+			return err
+		}
+		// The pending relocation is kept until the warp is published, so a failed publish is retried at the next login.
+		mb.OnEmit(func(err error) {
+			if err == nil {
+				getRelocationRegistry().Take(p.t, characterId, now)
+			}
+		})
+		return nil
+	}
+}
+
+func (p *ProcessorImpl) RelocateOnLoginAndEmit(characterId uint32, f field.Model) error {
+	return message.Emit(p.warpProducer())(func(mb *message.Buffer) error {
+		return p.RelocateOnLogin(mb)(characterId, f)
+	})
+}
+
+// ReconcilePassenger checks a character who entered a map. If it is a staging or en-route map of a route whose current
+// state no longer justifies them being there, they are warped out as the recovery sweep would.
+func (p *ProcessorImpl) ReconcilePassenger(mb *message.Buffer) func(characterId uint32, f field.Model) error {
+	return func(characterId uint32, f field.Model) error {
+		routes, err := p.aboardProvider(f)()
+		if err != nil {
+			return err
+		}
+		if len(routes) == 0 {
+			return nil
+		}
+		view, ok := p.recoverable(routes[0], f.WorldId(), f.ChannelId(), p.clk.Now())
+		if !ok {
+			return nil
+		}
+		targetMapId, stranded := view.StrandedTarget(f.MapId())
+		if !stranded {
+			p.l.Debugf("Character [%d] is in map [%d] while route [%s] is [%s], no relocation needed.", characterId, f.MapId(), view.Id(), view.State())
+			return nil
+		}
+		return p.relocate(mb)(view, characterId, f, targetMapId)
+	}
+}
+
+// aboardProvider returns the routes enabled in the field which passengers occupy its map while aboard.
+func (p *ProcessorImpl) aboardProvider(f field.Model) model.Provider[[]Model] {
+	return func() ([]Model, error) {
+		routes, err := model.FilteredProvider(p.ByMapIdProvider(f.MapId(), passengerMapRoles...), model.Filters(func(m Model) bool {
+			return m.EnabledIn(f.WorldId(), f.ChannelId())
+		}))()
+		if err != nil {
+			p.l.WithError(err).Error("Failed to get routes for tenant")
+		}
+		return routes, err
+	}
+}
+
+// relocate warps the character from the field to the target map of the same channel, as a recovery of the route.
+func (p *ProcessorImpl) relocate(mb *message.Buffer) func(route Model, characterId uint32, f field.Model, targetMapId map2.Id) error {
+	return func(route Model, characterId uint32, f field.Model, targetMapId map2.Id) error {
+		tf := field.NewBuilder(f.WorldId(), f.ChannelId(), targetMapId).Build()
+		return p.recoverPassenger(mb)(route)(passenger{characterId: characterId, movement: movement{from: f, to: tf}})
+	}
+}
+
+func (p *ProcessorImpl) ReconcilePassengerAndEmit(characterId uint32, f field.Model) error {
+	return message.Emit(p.warpProducer())(func(mb *message.Buffer) error {
+		return p.ReconcilePassenger(mb)(characterId, f)
 	})
 }
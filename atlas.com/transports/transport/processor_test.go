@@ -0,0 +1,213 @@
+package transport
+
+import (
+	"atlas-transports/clock"
+	"atlas-transports/kafka/message"
+	character2 "atlas-transports/kafka/message/character"
+	transport2 "atlas-transports/kafka/message/transport"
+	"atlas-transports/kafka/producer"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	channel2 "github.com/Chronicle20/atlas-constants/channel"
+	"github.com/Chronicle20/atlas-constants/field"
+	_map "github.com/Chronicle20/atlas-constants/map"
+	producer2 "github.com/Chronicle20/atlas-kafka/producer"
+	"github.com/Chronicle20/atlas-model/model"
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+// reconcileRoute returns a route running every half hour from midnight UTC, whose arrivals land on a fixed portal so
+// that no portal data is requested.
+func reconcileRoute(start time.Time) Model {
+	route := NewBuilder("Test Route").
+		SetId(uuid.New()).
+		SetStartMapId(100).
+		SetStagingMapId(101).
+		SetEnRouteMapIds([]_map.Id{102}).
+		SetDestinationMapId(103).
+		SetBoardingWindowDuration(5 * time.Minute).
+		SetPreDepartureDuration(2 * time.Minute).
+		SetTravelDuration(10 * time.Minute).
+		SetCycleInterval(30 * time.Minute).
+		SetArrivalWarp(NewWarpStrategy(WarpFixedPortal, "", 3)).
+		Build()
+	return ScheduleRoutes(clock.Fixed(start), []Model{route}, nil)[0]
+}
+
+// reconcileProcessor registers the route, as of now, for a new tenant.
+func reconcileProcessor(route Model, now time.Time) *ProcessorImpl {
+	l, _ := test.NewNullLogger()
+	ten, _ := tenant.Register(uuid.New(), "GMS", 83, 1)
+	route, _ = route.UpdateState(now)
+	getRouteRegistry().AddTenant(ten, []Model{route})
+	p := newProcessor(l, tenant.WithContext(context.Background(), ten))
+	p.clk = clock.Fixed(now)
+	return p
+}
+
+func TestRelocateOnLogin_KeepsPendingRelocationUntilRecoverable(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	route := reconcileRoute(start)
+	enRoute := field.NewBuilder(0, 1, 102).Build()
+
+	// The 12:00 trip arrived at 12:17, moments ago
+	now := start.Add(12*time.Hour + 17*time.Minute + 5*time.Second)
+	p := reconcileProcessor(route, now)
+	getRelocationRegistry().Add(p.t, NewPendingRelocationModel(1, route.Id(), 0, 102, InTransit, now.Add(-10*time.Minute)))
+
+	published := make(map[string]int)
+	fail := false
+	p.p = stubProducer(published, &fail)
+	login := func(mb *message.Buffer) error {
+		return p.RelocateOnLogin(mb)(1, enRoute)
+	}
+
+	assert.NoError(t, message.Emit(p.p)(login))
+	assert.Empty(t, published)
+	_, ok := getRelocationRegistry().Get(p.t, 1, now)
+	assert.True(t, ok)
+
+	// Once the grace period has passed the character is carried to the destination, but the relocation is kept until
+	// the warp is published
+	p.clk = clock.Fixed(now.Add(time.Minute))
+	fail = true
+	assert.Error(t, message.Emit(p.p)(login))
+	_, ok = getRelocationRegistry().Get(p.t, 1, now)
+	assert.True(t, ok)
+
+	fail = false
+	assert.NoError(t, message.Emit(p.p)(login))
+	assert.Equal(t, 1, published[character2.EnvCommandTopic])
+	assert.Equal(t, 1, published[transport2.EnvEventTopicPassenger])
+	_, ok = getRelocationRegistry().Get(p.t, 1, now)
+	assert.False(t, ok)
+}
+
+// stubProducer counts the messages produced to each topic, failing every produce call while fail is set.
+func stubProducer(published map[string]int, fail *bool) producer.Provider {
+	return func(token string) producer2.MessageProducer {
+		return func(provider model.Provider[[]kafka.Message]) error {
+			if *fail {
+				return errors.New("broker unavailable")
+			}
+			ms, err := provider()
+			published[token] += len(ms)
+			return err
+		}
+	}
+}
+
+func TestRelocateOnLogin_DiscardsPendingRelocationWhenNotStranded(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	route := reconcileRoute(start)
+
+	// The 12:30 trip is in transit, so the en-route map is where the character belongs
+	now := start.Add(12*time.Hour + 40*time.Minute)
+	p := reconcileProcessor(route, now)
+	getRelocationRegistry().Add(p.t, NewPendingRelocationModel(1, route.Id(), 0, 102, InTransit, now.Add(-time.Minute)))
+
+	mb := message.NewBuffer()
+	assert.NoError(t, p.RelocateOnLogin(mb)(1, field.NewBuilder(0, 1, 102).Build()))
+	assert.Empty(t, mb.GetAll())
+	_, ok := getRelocationRegistry().Get(p.t, 1, now)
+	assert.False(t, ok)
+}
+
+func TestReconcilePassenger_WarpsStrandedPassengers(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	route := reconcileRoute(start)
+	now := start.Add(12*time.Hour + 25*time.Minute)
+	p := reconcileProcessor(route, now)
+
+	mb := message.NewBuffer()
+	assert.NoError(t, p.ReconcilePassenger(mb)(1, field.NewBuilder(0, 1, 102).Build()))
+	assert.Len(t, mb.GetAll()[character2.EnvCommandTopic], 1)
+
+	// Recovery warps are not deduped, so a character stranded again on the same trip is moved again
+	for _, m := range mb.GetAll()[character2.EnvCommandTopic] {
+		for _, h := range m.Headers {
+			assert.NotEqual(t, producer.HeaderIdempotencyKey, h.Key)
+		}
+	}
+	mb = message.NewBuffer()
+	assert.NoError(t, p.ReconcilePassenger(mb)(1, field.NewBuilder(0, 1, 102).Build()))
+	assert.Len(t, mb.GetAll()[character2.EnvCommandTopic], 1)
+
+	// Entering a map which is not stranded does nothing
+	mb = message.NewBuffer()
+	assert.NoError(t, p.ReconcilePassenger(mb)(2, field.NewBuilder(0, 1, 100).Build()))
+	assert.Empty(t, mb.GetAll())
+}
+
+func TestModel_LoginTargetFollowsLogoutPolicy(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start.Add(12*time.Hour + 25*time.Minute)
+	route, _ := reconcileRoute(start).UpdateState(now)
+	assert.Equal(t, AwaitingReturn, route.State())
+
+	targetMapId, stranded := route.LoginTarget(102)
+	assert.True(t, stranded)
+	assert.Equal(t, _map.Id(100), targetMapId)
+
+	targetMapId, stranded = route.Builder().SetLogoutPolicy(AwaitingReturn, LogoutSendToDestination).Build().LoginTarget(102)
+	assert.True(t, stranded)
+	assert.Equal(t, _map.Id(103), targetMapId)
+
+	targetMapId, stranded = route.Builder().SetLogoutPolicy(AwaitingReturn, LogoutStay).Build().LoginTarget(101)
+	assert.True(t, stranded)
+	assert.Equal(t, _map.Id(100), targetMapId)
+
+	_, stranded = route.LoginTarget(100)
+	assert.False(t, stranded)
+}
+
+func TestTransition_StreamsEachReplayedStateOncePublished(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	route := reconcileRoute(start)
+	from, _ := route.UpdateState(start.Add(12*time.Hour + time.Minute))
+	now := start.Add(12*time.Hour + 7*time.Minute + time.Second)
+	to, _ := from.UpdateState(now)
+
+	p := reconcileProcessor(route, now)
+	p.chanP = stubChannels{channels: []channel2.Model{channel2.NewModel(0, 0)}}
+	p.occP = stubOccupancy{}
+	fail := true
+	p.p = stubProducer(make(map[string]int), &fail)
+	transition := func(mb *message.Buffer) error {
+		return p.Transition(mb)(from, to, now)
+	}
+
+	assert.Error(t, message.Emit(p.p)(transition))
+	assert.Empty(t, p.entered.Entered())
+
+	fail = false
+	assert.NoError(t, message.Emit(p.p)(transition))
+	entered := p.entered.Entered()
+	assert.Equal(t, []enteredState{{from: OpenEntry, to: LockedEntry}, {from: LockedEntry, to: InTransit}}, entered)
+
+	events := TransformStreamTransitions(to, p.recordTransition(from, to, now, 0, nil), entered)
+	if assert.Len(t, events, 2) {
+		assert.Nil(t, events[0].(StreamTransitionRestModel).ScheduledAt)
+		assert.Equal(t, start.Add(12*time.Hour+7*time.Minute), *events[1].(StreamTransitionRestModel).ScheduledAt)
+	}
+}
+
+func TestUpdateRoutes_WaitsForRouteUpdatesToFinish(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	route := reconcileRoute(start)
+	now := start.Add(12*time.Hour + 10*time.Minute)
+	p := reconcileProcessor(route, now)
+	p.p = stubProducer(make(map[string]int), new(bool))
+
+	assert.NoError(t, p.UpdateRoutes())
+	// The route's update has finished by the time the pass returns, so the next pass may update it again
+	assert.True(t, getInFlightRegistry().Acquire(p.t, route.Id()))
+	getInFlightRegistry().Release(p.t, route.Id())
+}
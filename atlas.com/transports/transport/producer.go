@@ -2,11 +2,14 @@ package transport
 
 import (
 	"atlas-transports/kafka/message/transport"
+	"github.com/Chronicle20/atlas-constants/channel"
 	_map "github.com/Chronicle20/atlas-constants/map"
+	"github.com/Chronicle20/atlas-constants/world"
 	"github.com/Chronicle20/atlas-kafka/producer"
 	"github.com/Chronicle20/atlas-model/model"
 	"github.com/google/uuid"
 	"github.com/segmentio/kafka-go"
+	"time"
 )
 
 func ArrivedStatusEventProvider(routeId uuid.UUID, mapId _map.Id) model.Provider[[]kafka.Message] {
@@ -30,3 +33,84 @@ func DepartedStatusEventProvider(routeId uuid.UUID, mapId _map.Id) model.Provide
 	}
 	return producer.SingleMessageProvider([]byte(routeId.String()), value)
 }
+
+// ChannelArrivedStatusEventProvider is an ARRIVED status event addressed to a single channel of a staggered route.
+func ChannelArrivedStatusEventProvider(routeId uuid.UUID, worldId world.Id, channelId channel.Id, mapId _map.Id) model.Provider[[]kafka.Message] {
+	value := transport.StatusEvent[transport.ArrivedStatusEventBody]{
+		RouteId: routeId,
+		Type:    transport.EventStatusArrived,
+		Body: transport.ArrivedStatusEventBody{
+			MapId:     mapId,
+			WorldId:   &worldId,
+			ChannelId: &channelId,
+		},
+	}
+	return producer.SingleMessageProvider([]byte(routeId.String()), value)
+}
+
+// ChannelDepartedStatusEventProvider is a DEPARTED status event addressed to a single channel of a staggered route.
+func ChannelDepartedStatusEventProvider(routeId uuid.UUID, worldId world.Id, channelId channel.Id, mapId _map.Id) model.Provider[[]kafka.Message] {
+	value := transport.StatusEvent[transport.DepartedStatusEventBody]{
+		RouteId: routeId,
+		Type:    transport.EventStatusDeparted,
+		Body: transport.DepartedStatusEventBody{
+			MapId:     mapId,
+			WorldId:   &worldId,
+			ChannelId: &channelId,
+		},
+	}
+	return producer.SingleMessageProvider([]byte(routeId.String()), value)
+}
+
+func SnapshotStatusEventProvider(routeId uuid.UUID, worldId world.Id, channelId channel.Id, startMapId _map.Id, destinationMapId _map.Id, observationMapId _map.Id, state RouteState, nextTransitionAt time.Time, remaining time.Duration) model.Provider[[]kafka.Message] {
+	value := transport.StatusEvent[transport.SnapshotStatusEventBody]{
+		RouteId: routeId,
+		Type:    transport.EventStatusSnapshot,
+		Body: transport.SnapshotStatusEventBody{
+			WorldId:          worldId,
+			ChannelId:        channelId,
+			StartMapId:       startMapId,
+			DestinationMapId: destinationMapId,
+			ObservationMapId: observationMapId,
+			State:            string(state),
+			NextTransitionAt: nextTransitionAt,
+			RemainingMillis:  remaining.Milliseconds(),
+		},
+	}
+	return producer.SingleMessageProvider([]byte(routeId.String()), value)
+}
+
+func RecoveredPassengerEventProvider(routeId uuid.UUID, worldId world.Id, channelId channel.Id, characterId uint32, fromMapId _map.Id, toMapId _map.Id, state RouteState) model.Provider[[]kafka.Message] {
+	key := producer.CreateKey(int(characterId))
+	value := transport.PassengerEvent[transport.RecoveredPassengerEventBody]{
+		RouteId:     routeId,
+		WorldId:     worldId,
+		CharacterId: characterId,
+		Type:        transport.PassengerEventTypeRecovered,
+		Body: transport.RecoveredPassengerEventBody{
+			ChannelId:  channelId,
+			FromMapId:  fromMapId,
+			ToMapId:    toMapId,
+			RouteState: string(state),
+		},
+	}
+	return producer.SingleMessageProvider(key, value)
+}
+
+func LogoutDecisionPassengerEventProvider(routeId uuid.UUID, worldId world.Id, channelId channel.Id, characterId uint32, mapId _map.Id, state RouteState, policy LogoutPolicy, targetMapId _map.Id) model.Provider[[]kafka.Message] {
+	key := producer.CreateKey(int(characterId))
+	value := transport.PassengerEvent[transport.LogoutDecisionPassengerEventBody]{
+		RouteId:     routeId,
+		WorldId:     worldId,
+		CharacterId: characterId,
+		Type:        transport.PassengerEventTypeLogout,
+		Body: transport.LogoutDecisionPassengerEventBody{
+			ChannelId:   channelId,
+			MapId:       mapId,
+			RouteState:  string(state),
+			Policy:      string(policy),
+			TargetMapId: targetMapId,
+		},
+	}
+	return producer.SingleMessageProvider(key, value)
+}
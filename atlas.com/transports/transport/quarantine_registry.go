@@ -0,0 +1,37 @@
+package transport
+
+import (
+	"sync"
+
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/google/uuid"
+)
+
+type QuarantineRegistry struct {
+	mutex       sync.RWMutex
+	quarantined map[uuid.UUID][]QuarantineModel
+}
+
+var quarantineRegistry *QuarantineRegistry
+var quarantineRegistryOnce sync.Once
+
+func getQuarantineRegistry() *QuarantineRegistry {
+	quarantineRegistryOnce.Do(func() {
+		quarantineRegistry = &QuarantineRegistry{}
+		quarantineRegistry.quarantined = make(map[uuid.UUID][]QuarantineModel)
+	})
+	return quarantineRegistry
+}
+
+// Set replaces the quarantined definitions of the tenant.
+func (r *QuarantineRegistry) Set(t tenant.Model, quarantined []QuarantineModel) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.quarantined[t.Id()] = append([]QuarantineModel{}, quarantined...)
+}
+
+func (r *QuarantineRegistry) Get(t tenant.Model) []QuarantineModel {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return append([]QuarantineModel{}, r.quarantined[t.Id()]...)
+}
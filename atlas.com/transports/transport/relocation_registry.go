@@ -0,0 +1,70 @@
+package transport
+
+import (
+	"sync"
+	"time"
+
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/google/uuid"
+)
+
+// pendingRelocationRetention is how long a relocation deferred at logout is kept for the character's next login. A
+// character away for longer is reconciled as any other login.
+const pendingRelocationRetention = 7 * 24 * time.Hour
+
+type RelocationRegistry struct {
+	mutex   sync.RWMutex
+	pending map[uuid.UUID]map[uint32]PendingRelocationModel
+}
+
+var relocationRegistry *RelocationRegistry
+var relocationRegistryOnce sync.Once
+
+func getRelocationRegistry() *RelocationRegistry {
+	relocationRegistryOnce.Do(func() {
+		relocationRegistry = &RelocationRegistry{}
+		relocationRegistry.pending = make(map[uuid.UUID]map[uint32]PendingRelocationModel)
+	})
+	return relocationRegistry
+}
+
+// Add records a relocation deferred at logout, pruning the tenant's relocations which have expired by the time it was
+// recorded.
+func (r *RelocationRegistry) Add(t tenant.Model, m PendingRelocationModel) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, ok := r.pending[t.Id()]; !ok {
+		r.pending[t.Id()] = make(map[uint32]PendingRelocationModel)
+	}
+	for characterId, pr := range r.pending[t.Id()] {
+		if pr.expired(m.RecordedAt()) {
+			delete(r.pending[t.Id()], characterId)
+		}
+	}
+	r.pending[t.Id()][m.CharacterId()] = m
+}
+
+// Get returns the pending relocation of the character, unless it has expired.
+func (r *RelocationRegistry) Get(t tenant.Model, characterId uint32, now time.Time) (PendingRelocationModel, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	if tenantPending, ok := r.pending[t.Id()]; ok {
+		if m, ok := tenantPending[characterId]; ok && !m.expired(now) {
+			return m, true
+		}
+	}
+	return PendingRelocationModel{}, false
+}
+
+// Take removes and returns the pending relocation of the character, unless it has expired.
+func (r *RelocationRegistry) Take(t tenant.Model, characterId uint32, now time.Time) (PendingRelocationModel, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if tenantPending, ok := r.pending[t.Id()]; ok {
+		if m, ok := tenantPending[characterId]; ok {
+			delete(tenantPending, characterId)
+			return m, !m.expired(now)
+		}
+	}
+	return PendingRelocationModel{}, false
+}
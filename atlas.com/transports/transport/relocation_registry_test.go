@@ -0,0 +1,40 @@
+package transport
+
+import (
+	"testing"
+	"time"
+
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelocationRegistry_ExpiresRelocations(t *testing.T) {
+	ten, _ := tenant.Register(uuid.New(), "GMS", 83, 1)
+	routeId := uuid.New()
+	now := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	r := getRelocationRegistry()
+
+	r.Add(ten, NewPendingRelocationModel(1, routeId, 0, 200090010, InTransit, now))
+	_, ok := r.Get(ten, 1, now.Add(pendingRelocationRetention))
+	assert.True(t, ok)
+	_, ok = r.Get(ten, 1, now.Add(pendingRelocationRetention+time.Second))
+	assert.False(t, ok)
+
+	r.Add(ten, NewPendingRelocationModel(2, routeId, 0, 200090010, InTransit, now.Add(pendingRelocationRetention+time.Second)))
+	r.mutex.RLock()
+	assert.Len(t, r.pending[ten.Id()], 1)
+	r.mutex.RUnlock()
+
+	pr, ok := r.Take(ten, 2, now.Add(pendingRelocationRetention))
+	assert.True(t, ok)
+	assert.Equal(t, uint32(2), pr.CharacterId())
+	_, ok = r.Take(ten, 2, now.Add(pendingRelocationRetention))
+	assert.False(t, ok)
+}
+
+func TestModel_LogoutPolicyForDefaultsToReturnToStart(t *testing.T) {
+	r := validationRoute("Ellinia to Orbis", time.Hour).Builder().SetLogoutPolicy(InTransit, LogoutPendingRelocation).Build()
+	assert.Equal(t, LogoutPendingRelocation, r.LogoutPolicyFor(InTransit))
+	assert.Equal(t, LogoutReturnToStart, r.LogoutPolicyFor(OpenEntry))
+}
@@ -1,14 +1,25 @@
 package transport
 
 import (
+	"atlas-transports/clock"
+	"atlas-transports/occupancy"
 	"atlas-transports/rest"
+	"cmp"
+	"fmt"
+	_map "github.com/Chronicle20/atlas-constants/map"
 	"github.com/Chronicle20/atlas-model/model"
 	"github.com/Chronicle20/atlas-rest/server"
+	tenant "github.com/Chronicle20/atlas-tenant"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/jtumidanski/api2go/jsonapi"
 	"github.com/sirupsen/logrus"
+	"io"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // InitResource registers the transport routes with the router
@@ -16,7 +27,20 @@ func InitResource(si jsonapi.ServerInformation) server.RouteInitializer {
 	return func(r *mux.Router, l logrus.FieldLogger) {
 		registerHandler := rest.RegisterHandler(l)(si)
 		r.HandleFunc("/transports/routes", registerHandler("get_all_routes", GetAllRoutesHandler)).Methods(http.MethodGet)
+		r.HandleFunc("/transports/routes/stream", registerHandler("stream_routes", StreamRoutesHandler)).Methods(http.MethodGet)
 		r.HandleFunc("/transports/routes/{routeId}", registerHandler("get_route", GetRouteHandler)).Methods(http.MethodGet)
+		r.HandleFunc("/transports/routes/{routeId}/occupancy", registerHandler("get_route_occupancy", GetRouteOccupancyHandler)).Methods(http.MethodGet)
+		r.HandleFunc("/transports/routes/{routeId}/history", registerHandler("get_route_history", GetRouteHistoryHandler)).Methods(http.MethodGet)
+		r.HandleFunc("/transports/routes/{routeId}/schedule.ics", registerHandler("get_route_schedule_ical", GetRouteScheduleExportHandler(icalExport))).Methods(http.MethodGet)
+		r.HandleFunc("/transports/routes/{routeId}/schedule.csv", registerHandler("get_route_schedule_csv", GetRouteScheduleExportHandler(csvExport))).Methods(http.MethodGet)
+		r.HandleFunc("/transports/schedule.ics", registerHandler("get_schedule_ical", GetScheduleExportHandler(icalExport))).Methods(http.MethodGet)
+		r.HandleFunc("/transports/schedule.csv", registerHandler("get_schedule_csv", GetScheduleExportHandler(csvExport))).Methods(http.MethodGet)
+		r.HandleFunc("/transports/quarantine", registerHandler("get_quarantine", GetQuarantineHandler)).Methods(http.MethodGet)
+		r.HandleFunc("/transports/network", registerHandler("get_network", GetNetworkHandler)).Methods(http.MethodGet)
+		r.HandleFunc("/transports/network.dot", registerHandler("get_network_dot", GetNetworkExportHandler(dotExport))).Methods(http.MethodGet)
+		r.HandleFunc("/transports/network.graphml", registerHandler("get_network_graphml", GetNetworkExportHandler(graphMLExport))).Methods(http.MethodGet)
+		r.HandleFunc("/transports/journeys", registerHandler("get_journeys", GetJourneysHandler)).Methods(http.MethodGet)
+		r.HandleFunc("/transports/pending-transitions", registerHandler("get_pending_transitions", GetPendingTransitionsHandler)).Methods(http.MethodGet)
 	}
 }
 
@@ -39,10 +63,262 @@ func GetRouteHandler(d *rest.HandlerDependency, c *rest.HandlerContext) http.Han
 	})
 }
 
-// GetAllRoutesHandler returns a handler for the GET /transports/routes endpoint
+// GetRouteOccupancyHandler returns a handler for the GET /transports/routes/:id/occupancy endpoint
+func GetRouteOccupancyHandler(d *rest.HandlerDependency, c *rest.HandlerContext) http.HandlerFunc {
+	return rest.ParseRouteId(d.Logger(), func(routeId uuid.UUID) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			rm, err := model.SliceMap(occupancy.Transform)(NewProcessor(d.Logger(), d.Context()).OccupancyProvider(routeId))(model.ParallelMap())()
+			if err != nil {
+				d.Logger().WithError(err).Errorln("Error retrieving route occupancy")
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+
+			query := r.URL.Query()
+			queryParams := jsonapi.ParseQueryFields(&query)
+			server.MarshalResponse[[]occupancy.RestModel](d.Logger())(w)(c.ServerInformation())(queryParams)(rm)
+		}
+	})
+}
+
+// GetRouteHistoryHandler returns a handler for the GET /transports/routes/:id/history endpoint. The optional since and
+// until query parameters bound the time the transitions occurred, as RFC 3339 timestamps.
+func GetRouteHistoryHandler(d *rest.HandlerDependency, c *rest.HandlerContext) http.HandlerFunc {
+	return rest.ParseRouteId(d.Logger(), func(routeId uuid.UUID) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			query := r.URL.Query()
+			since, err := parseTimeFilter(query.Get("since"))
+			if err != nil {
+				d.Logger().WithError(err).Errorf("Unable to properly parse since from query.")
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			until, err := parseTimeFilter(query.Get("until"))
+			if err != nil {
+				d.Logger().WithError(err).Errorf("Unable to properly parse until from query.")
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			rm, err := model.SliceMap(TransformTransitionRecord)(NewProcessor(d.Logger(), d.Context()).HistoryProvider(routeId, since, until))()()
+			if err != nil {
+				d.Logger().WithError(err).Errorln("Error retrieving route history")
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+
+			queryParams := jsonapi.ParseQueryFields(&query)
+			server.MarshalResponse[[]TransitionRecordRestModel](d.Logger())(w)(c.ServerInformation())(queryParams)(rm)
+		}
+	})
+}
+
+// maxScheduleWindow bounds the time window of a schedule export.
+const maxScheduleWindow = 31 * 24 * time.Hour
+
+// scheduleExport writes dated route schedules in a particular format.
+type scheduleExport struct {
+	contentType string
+	write       func(w io.Writer, name string, loc *time.Location, now time.Time) func(routes []Model) error
+}
+
+var icalExport = scheduleExport{
+	contentType: "text/calendar; charset=utf-8",
+	write:       WriteICalendar,
+}
+
+var csvExport = scheduleExport{
+	contentType: "text/csv; charset=utf-8",
+	write: func(w io.Writer, _ string, loc *time.Location, _ time.Time) func(routes []Model) error {
+		return WriteCSV(w, loc)
+	},
+}
+
+// GetRouteScheduleExportHandler returns a handler for the GET /transports/routes/:id/schedule.ics and .csv endpoints
+func GetRouteScheduleExportHandler(e scheduleExport) rest.GetHandler {
+	return func(d *rest.HandlerDependency, c *rest.HandlerContext) http.HandlerFunc {
+		return rest.ParseRouteId(d.Logger(), func(routeId uuid.UUID) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				p := NewProcessor(d.Logger(), d.Context())
+				exportSchedule(d, e, w, r, func(from time.Time, to time.Time) model.Provider[[]Model] {
+					return p.RouteScheduleProvider(routeId, from, to)
+				})
+			}
+		})
+	}
+}
+
+// GetScheduleExportHandler returns a handler for the GET /transports/schedule.ics and .csv endpoints, covering every
+// route of the tenant
+func GetScheduleExportHandler(e scheduleExport) rest.GetHandler {
+	return func(d *rest.HandlerDependency, c *rest.HandlerContext) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			exportSchedule(d, e, w, r, NewProcessor(d.Logger(), d.Context()).ScheduleProvider)
+		}
+	}
+}
+
+// exportSchedule writes the schedule within the window given by the optional from and to query parameters, as RFC 3339
+// timestamps or as dates in the tenant's zone. The window defaults to the tenant's day of the clock's current reading.
+func exportSchedule(d *rest.HandlerDependency, e scheduleExport, w http.ResponseWriter, r *http.Request, provider func(from time.Time, to time.Time) model.Provider[[]Model]) {
+	query := r.URL.Query()
+	loc := NewProcessor(d.Logger(), d.Context()).Timezone()
+	from, err := parseScheduleBound(query.Get("from"), loc)
+	if err != nil {
+		d.Logger().WithError(err).Errorf("Unable to properly parse from from query.")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	to, err := parseScheduleBound(query.Get("to"), loc)
+	if err != nil {
+		d.Logger().WithError(err).Errorf("Unable to properly parse to from query.")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	now := clock.NewProcessor(d.Logger(), d.Context()).Clock().Now()
+	from, to = scheduleWindow(from, to, loc, now)
+	if !from.Before(to) || to.Sub(from) > maxScheduleWindow {
+		d.Logger().Errorf("Schedule window [%s] to [%s] is empty or exceeds [%s].", from.Format(time.RFC3339), to.Format(time.RFC3339), maxScheduleWindow)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	routes, err := provider(from, to)()
+	if err != nil {
+		d.Logger().WithError(err).Errorln("Error retrieving route schedules")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	name := "Transports"
+	if len(routes) == 1 {
+		name = routes[0].Name()
+	}
+
+	w.Header().Set("Content-Type", e.contentType)
+	w.WriteHeader(http.StatusOK)
+	err = e.write(w, name, loc, now)(routes)
+	if err != nil {
+		d.Logger().WithError(err).Errorln("Error writing route schedules")
+	}
+}
+
+// parseTimeFilter parses an optional RFC 3339 query parameter. An absent parameter yields the zero time.
+func parseTimeFilter(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// StreamRoutesHandler returns a handler for the GET /transports/routes/stream endpoint. Route events of the tenant are
+// pushed as Server-Sent Events, starting with a snapshot unless the client resumes from a Last-Event-ID header, or a
+// lastEventId query parameter, which is still retained.
+func StreamRoutesHandler(d *rest.HandlerDependency, _ *rest.HandlerContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f, ok := w.(http.Flusher)
+		if !ok {
+			d.Logger().Errorf("Response writer does not support streaming.")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		lastEventIdValue := r.Header.Get("Last-Event-ID")
+		if lastEventIdValue == "" {
+			lastEventIdValue = r.URL.Query().Get("lastEventId")
+		}
+		var lastEventId uint64
+		resume := lastEventIdValue != ""
+		if resume {
+			var err error
+			lastEventId, err = strconv.ParseUint(lastEventIdValue, 10, 64)
+			if err != nil {
+				d.Logger().WithError(err).Errorf("Unable to properly parse last event id.")
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+
+		events, replay, unsubscribe := NewProcessor(d.Logger(), d.Context()).SubscribeStream(lastEventId, resume)
+		defer unsubscribe()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		for _, e := range replay {
+			if err := writeStreamEvent(w, e); err != nil {
+				return
+			}
+		}
+		f.Flush()
+
+		heartbeat := time.NewTicker(streamHeartbeatInterval)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case e, ok := <-events:
+				if !ok {
+					// The client fell too far behind, and reconnects from its last event id.
+					return
+				}
+				if err := writeStreamEvent(w, e); err != nil {
+					return
+				}
+				f.Flush()
+			case <-heartbeat.C:
+				if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+					return
+				}
+				f.Flush()
+			}
+		}
+	}
+}
+
+// GetAllRoutesHandler returns a handler for the GET /transports/routes endpoint. The optional filter[mapId] query
+// parameter restricts routes to those using the map in any role, and filter[state] to those in one of the comma
+// separated states. The optional sort query parameter orders routes by comma separated fields, each descending when
+// prefixed with a minus sign.
 func GetAllRoutesHandler(d *rest.HandlerDependency, c *rest.HandlerContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		rm, err := model.SliceMap(Transform)(NewProcessor(d.Logger(), d.Context()).AllRoutesProvider())(model.ParallelMap())()
+		query := r.URL.Query()
+		p := NewProcessor(d.Logger(), d.Context())
+		provider := p.AllRoutesProvider()
+		if value := query.Get("filter[mapId]"); value != "" {
+			mapId, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				d.Logger().WithError(err).Errorf("Unable to properly parse filter[mapId] from query.")
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			provider = p.ByMapIdProvider(_map.Id(mapId))
+		}
+		if value := query.Get("filter[state]"); value != "" {
+			states, err := parseStateFilter(value)
+			if err != nil {
+				d.Logger().WithError(err).Errorf("Unable to properly parse filter[state] from query.")
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			provider = model.FilteredProvider(provider, model.Filters(func(m Model) bool {
+				_, ok := states[m.State()]
+				return ok
+			}))
+		}
+		if value := query.Get("sort"); value != "" {
+			less, err := parseRouteSort(value)
+			if err != nil {
+				d.Logger().WithError(err).Errorf("Unable to properly parse sort from query.")
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			provider = sortedProvider(provider, less)
+		}
+
+		rm, err := model.SliceMap(Transform)(provider)()()
 		if err != nil {
 			d.Logger().WithError(err).Errorln("Error retrieving routes")
 			w.WriteHeader(http.StatusInternalServerError)
@@ -50,8 +326,219 @@ func GetAllRoutesHandler(d *rest.HandlerDependency, c *rest.HandlerContext) http
 		}
 
 		// Marshal response
-		query := r.URL.Query()
 		queryParams := jsonapi.ParseQueryFields(&query)
 		server.MarshalResponse[[]RestModel](d.Logger())(w)(c.ServerInformation())(queryParams)(rm)
 	}
 }
+
+// parseStateFilter parses a comma separated list of route states.
+func parseStateFilter(value string) (map[RouteState]struct{}, error) {
+	states := make(map[RouteState]struct{})
+	for _, v := range strings.Split(value, ",") {
+		state := RouteState(strings.TrimSpace(v))
+		if !slices.Contains(routeStates, state) {
+			return nil, fmt.Errorf("unknown route state [%s]", state)
+		}
+		states[state] = struct{}{}
+	}
+	return states, nil
+}
+
+// routeSortFields compares routes by each field the routes collection may be sorted by.
+var routeSortFields = map[string]func(a Model, b Model) int{
+	"name": func(a Model, b Model) int {
+		return strings.Compare(a.Name(), b.Name())
+	},
+	"state": func(a Model, b Model) int {
+		return strings.Compare(string(a.State()), string(b.State()))
+	},
+	"startMapId": func(a Model, b Model) int {
+		return cmp.Compare(a.StartMapId(), b.StartMapId())
+	},
+	"destinationMapId": func(a Model, b Model) int {
+		return cmp.Compare(a.DestinationMapId(), b.DestinationMapId())
+	},
+	"cycleInterval": func(a Model, b Model) int {
+		return cmp.Compare(a.CycleInterval(), b.CycleInterval())
+	},
+}
+
+// parseRouteSort parses a JSON:API sort parameter into a comparison of routes.
+func parseRouteSort(value string) (func(a Model, b Model) int, error) {
+	var comparisons []func(a Model, b Model) int
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		descending := strings.HasPrefix(field, "-")
+		compare, ok := routeSortFields[strings.TrimPrefix(field, "-")]
+		if !ok {
+			return nil, fmt.Errorf("unknown sort field [%s]", field)
+		}
+		if descending {
+			ascending := compare
+			compare = func(a Model, b Model) int {
+				return ascending(b, a)
+			}
+		}
+		comparisons = append(comparisons, compare)
+	}
+	return func(a Model, b Model) int {
+		for _, compare := range comparisons {
+			if c := compare(a, b); c != 0 {
+				return c
+			}
+		}
+		return 0
+	}, nil
+}
+
+// sortedProvider orders the routes of the provider. The order of routes comparing equal is preserved.
+func sortedProvider(provider model.Provider[[]Model], compare func(a Model, b Model) int) model.Provider[[]Model] {
+	return func() ([]Model, error) {
+		routes, err := provider()
+		if err != nil {
+			return nil, err
+		}
+		slices.SortStableFunc(routes, compare)
+		return routes, nil
+	}
+}
+
+// GetQuarantineHandler returns a handler for the GET /transports/quarantine endpoint
+func GetQuarantineHandler(d *rest.HandlerDependency, c *rest.HandlerContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rm, err := model.SliceMap(TransformQuarantine)(NewProcessor(d.Logger(), d.Context()).QuarantineProvider())()()
+		if err != nil {
+			d.Logger().WithError(err).Errorln("Error retrieving quarantined definitions")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		query := r.URL.Query()
+		queryParams := jsonapi.ParseQueryFields(&query)
+		server.MarshalResponse[[]QuarantineRestModel](d.Logger())(w)(c.ServerInformation())(queryParams)(rm)
+	}
+}
+
+// GetNetworkHandler returns a handler for the GET /transports/network endpoint
+func GetNetworkHandler(d *rest.HandlerDependency, c *rest.HandlerContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rm, err := model.Map(TransformNetwork(tenant.MustFromContext(d.Context()).Id()))(NewProcessor(d.Logger(), d.Context()).NetworkProvider())()
+		if err != nil {
+			d.Logger().WithError(err).Errorln("Error retrieving transport network")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		query := r.URL.Query()
+		queryParams := jsonapi.ParseQueryFields(&query)
+		server.MarshalResponse[NetworkRestModel](d.Logger())(w)(c.ServerInformation())(queryParams)(rm)
+	}
+}
+
+// networkExport writes the transport network in a particular format.
+type networkExport struct {
+	contentType string
+	write       func(w io.Writer) func(n NetworkModel) error
+}
+
+var dotExport = networkExport{
+	contentType: "text/vnd.graphviz; charset=utf-8",
+	write:       WriteDOT,
+}
+
+var graphMLExport = networkExport{
+	contentType: "application/graphml+xml; charset=utf-8",
+	write:       WriteGraphML,
+}
+
+// GetNetworkExportHandler returns a handler for the GET /transports/network.dot and .graphml endpoints
+func GetNetworkExportHandler(e networkExport) rest.GetHandler {
+	return func(d *rest.HandlerDependency, c *rest.HandlerContext) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			n, err := NewProcessor(d.Logger(), d.Context()).NetworkProvider()()
+			if err != nil {
+				d.Logger().WithError(err).Errorln("Error retrieving transport network")
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+
+			w.Header().Set("Content-Type", e.contentType)
+			w.WriteHeader(http.StatusOK)
+			if err = e.write(w)(n); err != nil {
+				d.Logger().WithError(err).Errorln("Error writing transport network")
+			}
+		}
+	}
+}
+
+const (
+	// defaultJourneyLimit is the number of itineraries returned when no limit is given.
+	defaultJourneyLimit = 5
+	// maxJourneyLimit bounds the number of itineraries which may be requested.
+	maxJourneyLimit = 20
+)
+
+// GetJourneysHandler returns a handler for the GET /transports/journeys endpoint. The fromMapId and toMapId query
+// parameters are required. The optional departAfter query parameter, as an RFC 3339 timestamp, defaults to the tenant
+// clock's current reading, and the optional limit to 5 itineraries.
+func GetJourneysHandler(d *rest.HandlerDependency, c *rest.HandlerContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		fromMapId, err := strconv.ParseUint(query.Get("fromMapId"), 10, 32)
+		if err != nil {
+			d.Logger().WithError(err).Errorf("Unable to properly parse fromMapId from query.")
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		toMapId, err := strconv.ParseUint(query.Get("toMapId"), 10, 32)
+		if err != nil {
+			d.Logger().WithError(err).Errorf("Unable to properly parse toMapId from query.")
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		departAfter, err := parseTimeFilter(query.Get("departAfter"))
+		if err != nil {
+			d.Logger().WithError(err).Errorf("Unable to properly parse departAfter from query.")
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if departAfter.IsZero() {
+			departAfter = clock.NewProcessor(d.Logger(), d.Context()).Clock().Now()
+		}
+		limit := defaultJourneyLimit
+		if value := query.Get("limit"); value != "" {
+			limit, err = strconv.Atoi(value)
+			if err != nil || limit <= 0 || limit > maxJourneyLimit {
+				d.Logger().WithError(err).Errorf("Limit [%s] must be between 1 and [%d].", value, maxJourneyLimit)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+
+		rm, err := model.SliceMap(TransformJourney)(NewProcessor(d.Logger(), d.Context()).JourneyProvider(_map.Id(fromMapId), _map.Id(toMapId), departAfter, limit))()()
+		if err != nil {
+			d.Logger().WithError(err).Errorln("Error planning journeys")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		queryParams := jsonapi.ParseQueryFields(&query)
+		server.MarshalResponse[[]JourneyRestModel](d.Logger())(w)(c.ServerInformation())(queryParams)(rm)
+	}
+}
+
+// GetPendingTransitionsHandler returns a handler for the GET /transports/pending-transitions endpoint
+func GetPendingTransitionsHandler(d *rest.HandlerDependency, c *rest.HandlerContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rm, err := model.SliceMap(TransformPendingTransition)(NewProcessor(d.Logger(), d.Context()).PendingTransitionsProvider())(model.ParallelMap())()
+		if err != nil {
+			d.Logger().WithError(err).Errorln("Error retrieving pending transitions")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		query := r.URL.Query()
+		queryParams := jsonapi.ParseQueryFields(&query)
+		server.MarshalResponse[[]PendingTransitionRestModel](d.Logger())(w)(c.ServerInformation())(queryParams)(rm)
+	}
+}
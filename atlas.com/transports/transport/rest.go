@@ -1,7 +1,9 @@
 package transport
 
 import (
+	"github.com/Chronicle20/atlas-constants/channel"
 	_map "github.com/Chronicle20/atlas-constants/map"
+	"github.com/Chronicle20/atlas-constants/world"
 	"github.com/Chronicle20/atlas-model/model"
 	"github.com/google/uuid"
 	"github.com/jtumidanski/api2go/jsonapi"
@@ -10,16 +12,21 @@ import (
 
 // RestModel is the JSON:API resource for a transport route
 type RestModel struct {
-	ID               uuid.UUID               `json:"-"`
-	Name             string                  `json:"name"`
-	StartMapID       _map.Id                 `json:"startMapId"`
-	StagingMapID     _map.Id                 `json:"stagingMapId"`
-	EnRouteMapIDs    []_map.Id               `json:"enRouteMapIds"`
-	DestinationMapID _map.Id                 `json:"destinationMapId"`
-	ObservationMapID _map.Id                 `json:"observationMapId"`
-	State            string                  `json:"state"`
-	CycleInterval    time.Duration           `json:"cycleInterval"`
-	Schedule         []TripScheduleRestModel `json:"-"`
+	ID               uuid.UUID                  `json:"-"`
+	Name             string                     `json:"name"`
+	StartMapID       _map.Id                    `json:"startMapId"`
+	StagingMapID     _map.Id                    `json:"stagingMapId"`
+	EnRouteMapIDs    []_map.Id                  `json:"enRouteMapIds"`
+	DestinationMapID _map.Id                    `json:"destinationMapId"`
+	ObservationMapID _map.Id                    `json:"observationMapId"`
+	State            string                     `json:"state"`
+	CycleInterval    time.Duration              `json:"cycleInterval"`
+	DepartureWarp    WarpStrategyRestModel      `json:"departureWarp"`
+	ArrivalWarp      WarpStrategyRestModel      `json:"arrivalWarp"`
+	LogoutPolicies   map[string]string          `json:"logoutPolicies"`
+	Enablement       []WorldEnablementRestModel `json:"enablement"`
+	ChannelStagger   time.Duration              `json:"channelStagger"`
+	Schedule         []TripScheduleRestModel    `json:"-"`
 }
 
 // GetID returns the resource ID
@@ -118,6 +125,11 @@ func Transform(m Model) (RestModel, error) {
 		ObservationMapID: m.ObservationMapId(),
 		State:            string(m.State()),
 		CycleInterval:    m.CycleInterval(),
+		DepartureWarp:    TransformWarpStrategy(m.DepartureWarp()),
+		ArrivalWarp:      TransformWarpStrategy(m.ArrivalWarp()),
+		LogoutPolicies:   TransformLogoutPolicies(m.LogoutPolicies()),
+		Enablement:       TransformEnablement(m.Enablement()),
+		ChannelStagger:   m.ChannelStagger(),
 		Schedule:         schedule,
 	}, nil
 }
@@ -142,9 +154,77 @@ func Extract(r RestModel) (Model, error) {
 		SetState(RouteState(r.State)).
 		SetSchedule(schedule).
 		SetCycleInterval(r.CycleInterval).
+		SetDepartureWarp(ExtractWarpStrategy(r.DepartureWarp)).
+		SetArrivalWarp(ExtractWarpStrategy(r.ArrivalWarp)).
+		SetLogoutPolicies(ExtractLogoutPolicies(r.LogoutPolicies)).
+		SetEnablement(ExtractEnablement(r.Enablement)).
+		SetChannelStagger(r.ChannelStagger).
 		Build(), nil
 }
 
+// WarpStrategyRestModel is the JSON representation of a warp strategy
+type WarpStrategyRestModel struct {
+	Type       string `json:"type"`
+	PortalName string `json:"portalName,omitempty"`
+	PortalId   uint32 `json:"portalId,omitempty"`
+}
+
+// TransformWarpStrategy converts a WarpStrategy to a WarpStrategyRestModel
+func TransformWarpStrategy(s WarpStrategy) WarpStrategyRestModel {
+	return WarpStrategyRestModel{
+		Type:       string(s.Type()),
+		PortalName: s.PortalName(),
+		PortalId:   s.PortalId(),
+	}
+}
+
+// ExtractWarpStrategy converts a WarpStrategyRestModel to a WarpStrategy
+func ExtractWarpStrategy(r WarpStrategyRestModel) WarpStrategy {
+	return NewWarpStrategy(WarpStrategyType(r.Type), r.PortalName, r.PortalId)
+}
+
+// TransformLogoutPolicies converts logout policies keyed by route state to their JSON representation
+func TransformLogoutPolicies(ps map[RouteState]LogoutPolicy) map[string]string {
+	rm := make(map[string]string, len(ps))
+	for state, policy := range ps {
+		rm[string(state)] = string(policy)
+	}
+	return rm
+}
+
+// ExtractLogoutPolicies converts the JSON representation of logout policies to policies keyed by route state
+func ExtractLogoutPolicies(rm map[string]string) map[RouteState]LogoutPolicy {
+	ps := make(map[RouteState]LogoutPolicy, len(rm))
+	for state, policy := range rm {
+		ps[RouteState(state)] = LogoutPolicy(policy)
+	}
+	return ps
+}
+
+// WorldEnablementRestModel is the JSON representation of the channels of a world a route runs in
+type WorldEnablementRestModel struct {
+	WorldId    world.Id     `json:"worldId"`
+	ChannelIds []channel.Id `json:"channelIds,omitempty"`
+}
+
+// TransformEnablement converts route enablement to its JSON representation
+func TransformEnablement(es []WorldEnablementModel) []WorldEnablementRestModel {
+	rm := make([]WorldEnablementRestModel, 0, len(es))
+	for _, e := range es {
+		rm = append(rm, WorldEnablementRestModel{WorldId: e.WorldId(), ChannelIds: e.ChannelIds()})
+	}
+	return rm
+}
+
+// ExtractEnablement converts the JSON representation of route enablement to its domain model
+func ExtractEnablement(rm []WorldEnablementRestModel) []WorldEnablementModel {
+	es := make([]WorldEnablementModel, 0, len(rm))
+	for _, r := range rm {
+		es = append(es, NewWorldEnablementModel(r.WorldId, r.ChannelIds))
+	}
+	return es
+}
+
 // TripScheduleRestModel is the JSON:API resource for a trip schedule
 type TripScheduleRestModel struct {
 	ID             uuid.UUID `json:"-"`
@@ -194,3 +274,293 @@ func ExtractSchedule(r TripScheduleRestModel) (TripScheduleModel, error) {
 		SetArrival(r.Arrival).
 		Build(), nil
 }
+
+// PendingTransitionRestModel is the JSON:API resource for a transition awaiting a retry
+type PendingTransitionRestModel struct {
+	RouteID       uuid.UUID `json:"-"`
+	FromState     string    `json:"fromState"`
+	ToState       string    `json:"toState"`
+	Attempts      int       `json:"attempts"`
+	FirstFailedAt time.Time `json:"firstFailedAt"`
+	LastFailedAt  time.Time `json:"lastFailedAt"`
+	NextAttemptAt time.Time `json:"nextAttemptAt"`
+	LastError     string    `json:"lastError"`
+}
+
+// GetID returns the resource ID
+func (r PendingTransitionRestModel) GetID() string {
+	return r.RouteID.String()
+}
+
+// SetID sets the resource ID
+func (r *PendingTransitionRestModel) SetID(idStr string) error {
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return err
+	}
+	r.RouteID = id
+	return nil
+}
+
+// GetName returns the resource name
+func (r PendingTransitionRestModel) GetName() string {
+	return "pending-transitions"
+}
+
+// TransformPendingTransition converts a PendingTransitionModel to a PendingTransitionRestModel
+func TransformPendingTransition(m PendingTransitionModel) (PendingTransitionRestModel, error) {
+	return PendingTransitionRestModel{
+		RouteID:       m.RouteId(),
+		FromState:     string(m.FromState()),
+		ToState:       string(m.ToState()),
+		Attempts:      m.Attempts(),
+		FirstFailedAt: m.FirstFailedAt(),
+		LastFailedAt:  m.LastFailedAt(),
+		NextAttemptAt: m.NextAttemptAt(),
+		LastError:     m.LastError(),
+	}, nil
+}
+
+// TransitionRecordRestModel is the JSON:API resource for a recorded route transition
+type TransitionRecordRestModel struct {
+	Id               uuid.UUID `json:"-"`
+	RouteId          uuid.UUID `json:"routeId"`
+	FromState        string    `json:"fromState"`
+	ToState          string    `json:"toState"`
+	ScheduledAt      time.Time `json:"scheduledAt"`
+	OccurredAt       time.Time `json:"occurredAt"`
+	TripOccurrenceId string    `json:"tripOccurrenceId,omitempty"`
+	PassengersMoved  int       `json:"passengersMoved"`
+	Attempts         int       `json:"attempts"`
+	Succeeded        bool      `json:"succeeded"`
+	Error            string    `json:"error,omitempty"`
+}
+
+// GetID returns the resource ID
+func (r TransitionRecordRestModel) GetID() string {
+	return r.Id.String()
+}
+
+// SetID sets the resource ID
+func (r *TransitionRecordRestModel) SetID(idStr string) error {
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return err
+	}
+	r.Id = id
+	return nil
+}
+
+// GetName returns the resource name
+func (r TransitionRecordRestModel) GetName() string {
+	return "transitions"
+}
+
+// TransformTransitionRecord converts a TransitionRecordModel to a TransitionRecordRestModel
+func TransformTransitionRecord(m TransitionRecordModel) (TransitionRecordRestModel, error) {
+	return TransitionRecordRestModel{
+		Id:               m.Id(),
+		RouteId:          m.RouteId(),
+		FromState:        string(m.FromState()),
+		ToState:          string(m.ToState()),
+		ScheduledAt:      m.ScheduledAt(),
+		OccurredAt:       m.OccurredAt(),
+		TripOccurrenceId: m.TripOccurrenceId(),
+		PassengersMoved:  m.PassengersMoved(),
+		Attempts:         m.Attempts(),
+		Succeeded:        m.Succeeded(),
+		Error:            m.Error(),
+	}, nil
+}
+
+// JourneyRestModel is the JSON:API resource for an itinerary between two maps
+type JourneyRestModel struct {
+	Id       string                `json:"-"`
+	BoardAt  time.Time             `json:"boardAt"`
+	Arrival  time.Time             `json:"arrival"`
+	Duration time.Duration         `json:"duration"`
+	Legs     []JourneyLegRestModel `json:"legs"`
+}
+
+// JourneyLegRestModel is a single trip of an itinerary
+type JourneyLegRestModel struct {
+	RouteId          uuid.UUID     `json:"routeId"`
+	RouteName        string        `json:"routeName"`
+	FromMapId        _map.Id       `json:"fromMapId"`
+	ToMapId          _map.Id       `json:"toMapId"`
+	TripOccurrenceId string        `json:"tripOccurrenceId"`
+	Connection       time.Duration `json:"connection"`
+	BoardAt          time.Time     `json:"boardAt"`
+	BoardingOpen     time.Time     `json:"boardingOpen"`
+	BoardingClosed   time.Time     `json:"boardingClosed"`
+	Departure        time.Time     `json:"departure"`
+	Arrival          time.Time     `json:"arrival"`
+}
+
+// GetID returns the resource ID
+func (r JourneyRestModel) GetID() string {
+	return r.Id
+}
+
+// SetID sets the resource ID
+func (r *JourneyRestModel) SetID(id string) error {
+	r.Id = id
+	return nil
+}
+
+// GetName returns the resource name
+func (r JourneyRestModel) GetName() string {
+	return "journeys"
+}
+
+// TransformJourney converts a JourneyModel to a JourneyRestModel
+func TransformJourney(m JourneyModel) (JourneyRestModel, error) {
+	legs := make([]JourneyLegRestModel, 0, len(m.Legs()))
+	for _, l := range m.Legs() {
+		legs = append(legs, JourneyLegRestModel{
+			RouteId:          l.RouteId(),
+			RouteName:        l.RouteName(),
+			FromMapId:        l.FromMapId(),
+			ToMapId:          l.ToMapId(),
+			TripOccurrenceId: l.TripOccurrenceId(),
+			Connection:       l.Connection(),
+			BoardAt:          l.BoardAt(),
+			BoardingOpen:     l.Trip().BoardingOpen(),
+			BoardingClosed:   l.Trip().BoardingClosed(),
+			Departure:        l.Trip().Departure(),
+			Arrival:          l.Trip().Arrival(),
+		})
+	}
+	return JourneyRestModel{
+		Id:       m.Id(),
+		BoardAt:  m.BoardAt(),
+		Arrival:  m.Arrival(),
+		Duration: m.Duration(),
+		Legs:     legs,
+	}, nil
+}
+
+// NetworkRestModel is the JSON:API resource for the transport network of a tenant
+type NetworkRestModel struct {
+	Id      uuid.UUID              `json:"-"`
+	Regions int                    `json:"regions"`
+	Nodes   []NetworkNodeRestModel `json:"nodes"`
+	Edges   []NetworkEdgeRestModel `json:"edges"`
+}
+
+// NetworkNodeRestModel is a map of the transport network
+type NetworkNodeRestModel struct {
+	MapId  _map.Id `json:"mapId"`
+	Region int     `json:"region"`
+}
+
+// NetworkEdgeRestModel is a route or shared vessel of the transport network
+type NetworkEdgeRestModel struct {
+	Id              uuid.UUID     `json:"id"`
+	Kind            string        `json:"kind"`
+	Name            string        `json:"name"`
+	SourceMapId     _map.Id       `json:"sourceMapId"`
+	TargetMapId     _map.Id       `json:"targetMapId"`
+	TripDuration    time.Duration `json:"tripDuration"`
+	TripsPerDay     int           `json:"tripsPerDay"`
+	Headway         time.Duration `json:"headway"`
+	TurnaroundDelay time.Duration `json:"turnaroundDelay,omitempty"`
+}
+
+// GetID returns the resource ID
+func (r NetworkRestModel) GetID() string {
+	return r.Id.String()
+}
+
+// SetID sets the resource ID
+func (r *NetworkRestModel) SetID(idStr string) error {
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return err
+	}
+	r.Id = id
+	return nil
+}
+
+// GetName returns the resource name
+func (r NetworkRestModel) GetName() string {
+	return "networks"
+}
+
+// TransformNetwork converts a NetworkModel of the tenant to a NetworkRestModel
+func TransformNetwork(tenantId uuid.UUID) model.Transformer[NetworkModel, NetworkRestModel] {
+	return func(m NetworkModel) (NetworkRestModel, error) {
+		nodes := make([]NetworkNodeRestModel, 0, len(m.Nodes()))
+		for _, n := range m.Nodes() {
+			nodes = append(nodes, NetworkNodeRestModel{MapId: n.MapId(), Region: n.Region()})
+		}
+		edges := make([]NetworkEdgeRestModel, 0, len(m.Edges()))
+		for _, e := range m.Edges() {
+			edges = append(edges, NetworkEdgeRestModel{
+				Id:              e.Id(),
+				Kind:            e.Kind(),
+				Name:            e.Name(),
+				SourceMapId:     e.SourceMapId(),
+				TargetMapId:     e.TargetMapId(),
+				TripDuration:    e.TripDuration(),
+				TripsPerDay:     e.TripsPerDay(),
+				Headway:         e.Headway(),
+				TurnaroundDelay: e.TurnaroundDelay(),
+			})
+		}
+		return NetworkRestModel{
+			Id:      tenantId,
+			Regions: m.Regions(),
+			Nodes:   nodes,
+			Edges:   edges,
+		}, nil
+	}
+}
+
+// QuarantineRestModel is the JSON:API resource for a route or shared vessel definition which failed validation
+type QuarantineRestModel struct {
+	Id     uuid.UUID             `json:"-"`
+	Kind   string                `json:"kind"`
+	Name   string                `json:"name"`
+	Errors []FieldErrorRestModel `json:"errors"`
+}
+
+// FieldErrorRestModel is a problem with a single field of a definition
+type FieldErrorRestModel struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// GetID returns the resource ID
+func (r QuarantineRestModel) GetID() string {
+	return r.Id.String()
+}
+
+// SetID sets the resource ID
+func (r *QuarantineRestModel) SetID(idStr string) error {
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return err
+	}
+	r.Id = id
+	return nil
+}
+
+// GetName returns the resource name
+func (r QuarantineRestModel) GetName() string {
+	return "quarantines"
+}
+
+// TransformQuarantine converts a QuarantineModel to a QuarantineRestModel
+func TransformQuarantine(m QuarantineModel) (QuarantineRestModel, error) {
+	errs := make([]FieldErrorRestModel, 0, len(m.Errors()))
+	for _, fe := range m.Errors() {
+		errs = append(errs, FieldErrorRestModel{Field: fe.Field(), Message: fe.Message()})
+	}
+	return QuarantineRestModel{
+		Id:     m.Id(),
+		Kind:   m.Kind(),
+		Name:   m.Name(),
+		Errors: errs,
+	}, nil
+}
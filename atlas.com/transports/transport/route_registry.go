@@ -1,14 +1,40 @@
 package transport
 
 import (
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	_map "github.com/Chronicle20/atlas-constants/map"
 	tenant "github.com/Chronicle20/atlas-tenant"
 	"github.com/google/uuid"
-	"sync"
 )
 
+// MapRole is the part a map plays in a route.
+type MapRole string
+
+const (
+	MapRoleStart       MapRole = "start"
+	MapRoleStaging     MapRole = "staging"
+	MapRoleEnRoute     MapRole = "en_route"
+	MapRoleDestination MapRole = "destination"
+	MapRoleObservation MapRole = "observation"
+)
+
+// mapRoles is every part a map can play in a route.
+var mapRoles = []MapRole{MapRoleStart, MapRoleStaging, MapRoleEnRoute, MapRoleDestination, MapRoleObservation}
+
+// passengerMapRoles are the parts played by maps which passengers occupy while aboard.
+var passengerMapRoles = []MapRole{MapRoleStaging, MapRoleEnRoute}
+
+// mapIndex locates the routes of a tenant by the maps they use, for each part a map plays.
+type mapIndex map[MapRole]map[_map.Id][]uuid.UUID
+
 type RouteRegistry struct {
 	mutex         sync.RWMutex
 	routeRegister map[uuid.UUID]map[uuid.UUID]Model
+	mapRegister   map[uuid.UUID]mapIndex
 }
 
 var routeRegistry *RouteRegistry
@@ -18,6 +44,7 @@ func getRouteRegistry() *RouteRegistry {
 	routeRegistryOnce.Do(func() {
 		routeRegistry = &RouteRegistry{}
 		routeRegistry.routeRegister = make(map[uuid.UUID]map[uuid.UUID]Model)
+		routeRegistry.mapRegister = make(map[uuid.UUID]mapIndex)
 	})
 	return routeRegistry
 }
@@ -32,7 +59,7 @@ func (r *RouteRegistry) AddTenant(t tenant.Model, routes []Model) {
 		r.routeRegister[t.Id()] = tenantStates
 	}
 	for _, route := range routes {
-		tenantStates[route.Id()] = route
+		r.put(t, route)
 	}
 }
 
@@ -50,25 +77,160 @@ func (r *RouteRegistry) GetRoute(t tenant.Model, id uuid.UUID) (Model, bool) {
 	return Model{}, false
 }
 
+// GetRoutes returns every route of the tenant, ordered by name.
 func (r *RouteRegistry) GetRoutes(t tenant.Model) ([]Model, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 	if tenantRegister, ok := r.routeRegister[t.Id()]; ok {
-		var routes []Model
+		routes := make([]Model, 0, len(tenantRegister))
 		for _, route := range tenantRegister {
 			routes = append(routes, route)
 		}
-		return routes, nil
+		return sortRoutes(routes), nil
 	}
 	return make([]Model, 0), nil
 }
 
+// GetRoutesByMap returns the routes of the tenant which use the map in any of the given roles, or in any role at all
+// when none are given, ordered by name.
+func (r *RouteRegistry) GetRoutesByMap(t tenant.Model, mapId _map.Id, roles ...MapRole) []Model {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	if len(roles) == 0 {
+		roles = mapRoles
+	}
+	idx, ok := r.mapRegister[t.Id()]
+	if !ok {
+		return make([]Model, 0)
+	}
+	seen := make(map[uuid.UUID]struct{})
+	routes := make([]Model, 0)
+	for _, role := range roles {
+		for _, id := range idx[role][mapId] {
+			if _, ok := seen[id]; ok {
+				continue
+			}
+			seen[id] = struct{}{}
+			if route, ok := r.routeRegister[t.Id()][id]; ok {
+				routes = append(routes, route)
+			}
+		}
+	}
+	return sortRoutes(routes)
+}
+
 func (r *RouteRegistry) UpdateRoute(t tenant.Model, route Model) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	if _, ok := r.routeRegister[t.Id()]; !ok {
 		r.routeRegister[t.Id()] = make(map[uuid.UUID]Model)
 	}
-	r.routeRegister[t.Id()][route.Id()] = route
+	r.put(t, route)
 	return nil
 }
+
+// put stores the route, reindexing its maps only when they differ from those of the route it replaces. Most updates
+// only advance the route's state.
+func (r *RouteRegistry) put(t tenant.Model, route Model) {
+	existing, ok := r.routeRegister[t.Id()][route.Id()]
+	r.routeRegister[t.Id()][route.Id()] = route
+	if ok && sameMaps(existing, route) {
+		return
+	}
+	if ok {
+		r.unindex(t, existing)
+	}
+	r.index(t, route)
+}
+
+// Touch records the clock reading the state of the route was last evaluated at, when the evaluation left it unchanged.
+func (r *RouteRegistry) Touch(t tenant.Model, id uuid.UUID, at time.Time) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if route, ok := r.routeRegister[t.Id()][id]; ok {
+		r.routeRegister[t.Id()][id] = route.Builder().SetUpdatedAt(at).Build()
+	}
+}
+
+// mapsByRole returns the maps the route uses in each role.
+func mapsByRole(route Model) map[MapRole][]_map.Id {
+	return map[MapRole][]_map.Id{
+		MapRoleStart:       {route.StartMapId()},
+		MapRoleStaging:     {route.StagingMapId()},
+		MapRoleEnRoute:     route.EnRouteMapIds(),
+		MapRoleDestination: {route.DestinationMapId()},
+		MapRoleObservation: {route.ObservationMapId()},
+	}
+}
+
+// sameMaps reports whether the routes use the same maps in every role.
+func sameMaps(a Model, b Model) bool {
+	bm := mapsByRole(b)
+	for role, mapIds := range mapsByRole(a) {
+		if !slices.Equal(mapIds, bm[role]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *RouteRegistry) index(t tenant.Model, route Model) {
+	idx, ok := r.mapRegister[t.Id()]
+	if !ok {
+		idx = make(mapIndex)
+		r.mapRegister[t.Id()] = idx
+	}
+	for role, mapIds := range mapsByRole(route) {
+		if _, ok := idx[role]; !ok {
+			idx[role] = make(map[_map.Id][]uuid.UUID)
+		}
+		for _, mapId := range mapIds {
+			if containsId(idx[role][mapId], route.Id()) {
+				continue
+			}
+			idx[role][mapId] = append(idx[role][mapId], route.Id())
+		}
+	}
+}
+
+func (r *RouteRegistry) unindex(t tenant.Model, route Model) {
+	idx, ok := r.mapRegister[t.Id()]
+	if !ok {
+		return
+	}
+	for role, mapIds := range mapsByRole(route) {
+		for _, mapId := range mapIds {
+			remaining := make([]uuid.UUID, 0)
+			for _, id := range idx[role][mapId] {
+				if id != route.Id() {
+					remaining = append(remaining, id)
+				}
+			}
+			if len(remaining) == 0 {
+				delete(idx[role], mapId)
+				continue
+			}
+			idx[role][mapId] = remaining
+		}
+	}
+}
+
+func containsId(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+// sortRoutes orders routes by name, and by id amongst routes of the same name.
+func sortRoutes(routes []Model) []Model {
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Name() != routes[j].Name() {
+			return routes[i].Name() < routes[j].Name()
+		}
+		return routes[i].Id().String() < routes[j].Id().String()
+	})
+	return routes
+}
@@ -0,0 +1,48 @@
+package transport
+
+import (
+	"testing"
+
+	_map "github.com/Chronicle20/atlas-constants/map"
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteRegistry_GetRoutesByMap(t *testing.T) {
+	ten, _ := tenant.Register(uuid.New(), "GMS", 83, 1)
+	r := getRouteRegistry()
+
+	ellinia := NewBuilder("Ellinia to Orbis").SetStartMapId(101000300).SetStagingMapId(101000301).SetEnRouteMapIds([]_map.Id{200090010}).SetDestinationMapId(200000100).SetObservationMapId(101000300).Build()
+	orbis := NewBuilder("Orbis to Ellinia").SetStartMapId(200000100).SetStagingMapId(200000112).SetEnRouteMapIds([]_map.Id{200090000}).SetDestinationMapId(101000300).SetObservationMapId(200000100).Build()
+	r.AddTenant(ten, []Model{orbis, ellinia})
+
+	routes, _ := r.GetRoutes(ten)
+	assert.Equal(t, []uuid.UUID{ellinia.Id(), orbis.Id()}, routeIds(routes))
+	assert.Equal(t, []uuid.UUID{ellinia.Id(), orbis.Id()}, routeIds(r.GetRoutesByMap(ten, 101000300)))
+	assert.Equal(t, []uuid.UUID{orbis.Id()}, routeIds(r.GetRoutesByMap(ten, 101000300, MapRoleDestination)))
+	assert.Equal(t, []uuid.UUID{ellinia.Id()}, routeIds(r.GetRoutesByMap(ten, 200090010, passengerMapRoles...)))
+	assert.Empty(t, r.GetRoutesByMap(ten, 101000300, passengerMapRoles...))
+
+	_ = r.UpdateRoute(ten, ellinia.Builder().SetStagingMapId(101000302).SetState(OpenEntry).Build())
+	assert.Empty(t, r.GetRoutesByMap(ten, 101000301))
+	routes = r.GetRoutesByMap(ten, 101000302, MapRoleStaging)
+	assert.Len(t, routes, 1)
+	assert.Equal(t, OpenEntry, routes[0].State())
+
+	// A change of state leaves the index alone, yet the index serves the latest state
+	_ = r.UpdateRoute(ten, routes[0].Builder().SetState(LockedEntry).Build())
+	routes = r.GetRoutesByMap(ten, 101000302, MapRoleStaging)
+	assert.Len(t, routes, 1)
+	assert.Equal(t, LockedEntry, routes[0].State())
+	assert.True(t, sameMaps(ellinia.Builder().SetStagingMapId(101000302).Build(), routes[0]))
+	assert.False(t, sameMaps(ellinia, routes[0]))
+}
+
+func routeIds(routes []Model) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(routes))
+	for _, r := range routes {
+		ids = append(ids, r.Id())
+	}
+	return ids
+}
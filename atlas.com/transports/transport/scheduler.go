@@ -1,26 +1,28 @@
 package transport
 
 import (
+	"atlas-transports/clock"
 	"github.com/google/uuid"
 	"time"
 )
 
-var timeNow = time.Now
-
 type Scheduler struct {
+	clock         clock.Clock
 	routes        []Model
 	sharedVessels []SharedVesselModel
 }
 
-func NewScheduler(routes []Model, sharedVessels []SharedVesselModel) *Scheduler {
+// NewScheduler creates a scheduler which lays out the trips of the day the clock currently reads.
+func NewScheduler(c clock.Clock, routes []Model, sharedVessels []SharedVesselModel) *Scheduler {
 	return &Scheduler{
+		clock:         c,
 		routes:        routes,
 		sharedVessels: sharedVessels,
 	}
 }
 
 func (s *Scheduler) ComputeSchedule() []TripScheduleModel {
-	now := timeNow().UTC()
+	now := s.clock.Now().UTC()
 	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
 	endOfDay := startOfDay.Add(24 * time.Hour)
 
@@ -48,8 +50,13 @@ func (s *Scheduler) ComputeSchedule() []TripScheduleModel {
 	return schedules
 }
 
+// computeRouteSchedule lays out the trips of an unshared route, one per cycle interval. A route without a positive
+// cycle interval would never finish, so it is given no trips.
 func (s *Scheduler) computeRouteSchedule(route Model, startOfDay, endOfDay time.Time) []TripScheduleModel {
 	var schedules []TripScheduleModel
+	if route.CycleInterval() <= 0 {
+		return schedules
+	}
 	currentTime := startOfDay
 
 	for currentTime.Before(endOfDay) {
@@ -60,6 +67,7 @@ func (s *Scheduler) computeRouteSchedule(route Model, startOfDay, endOfDay time.
 
 		if arrival.Before(endOfDay) {
 			schedule := NewTripScheduleBuilder().
+				SetTripId(tripId(route.Id(), boardingOpen)).
 				SetRouteId(route.Id()).
 				SetBoardingOpen(boardingOpen).
 				SetBoardingClosed(boardingClosed).
@@ -108,6 +116,7 @@ func (s *Scheduler) computeSharedVesselSchedule(vessel SharedVesselModel, startO
 
 		if arrival.Before(endOfDay) {
 			schedule := NewTripScheduleBuilder().
+				SetTripId(tripId(route.Id(), boardingOpen)).
 				SetRouteId(route.Id()).
 				SetBoardingOpen(boardingOpen).
 				SetBoardingClosed(boardingClosed).
@@ -123,3 +132,27 @@ func (s *Scheduler) computeSharedVesselSchedule(vessel SharedVesselModel, startO
 
 	return schedules
 }
+
+// ScheduleRoutes attaches the trips of the day the clock currently reads to each of the routes. The definitions should
+// have passed ValidateConfiguration, as an unshared route without a positive cycle interval is left without trips.
+func ScheduleRoutes(c clock.Clock, routes []Model, sharedVessels []SharedVesselModel) []Model {
+	trips := make(map[uuid.UUID][]TripScheduleModel)
+	for _, trip := range NewScheduler(c, routes, sharedVessels).ComputeSchedule() {
+		trips[trip.RouteId()] = append(trips[trip.RouteId()], trip)
+	}
+	results := make([]Model, 0, len(routes))
+	for _, route := range routes {
+		b := route.Builder()
+		for _, trip := range trips[route.Id()] {
+			b.AddToSchedule(trip)
+		}
+		results = append(results, b.Build())
+	}
+	return results
+}
+
+// tripId derives a deterministic trip identifier from the route and the time of day boarding opens, so that the same
+// trip keeps its identity across recomputation and replicas.
+func tripId(routeId uuid.UUID, boardingOpen time.Time) uuid.UUID {
+	return uuid.NewSHA1(routeId, []byte(boardingOpen.Format("15:04:05.000000000")))
+}
@@ -1,6 +1,7 @@
 package transport
 
 import (
+	"atlas-transports/clock"
 	"testing"
 	"time"
 
@@ -23,9 +24,6 @@ func scheduleForRoute(routeId uuid.UUID, trips []TripScheduleModel) []TripSchedu
 
 func TestScheduler_ComputeSchedule_SharedVesselOverridesRouteSchedule(t *testing.T) {
 	fixedTime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
-	originalTimeNow := timeNow
-	timeNow = func() time.Time { return fixedTime }
-	defer func() { timeNow = originalTimeNow }()
 
 	routeA := NewBuilder("Route A").
 		SetStartMapId(100).
@@ -73,7 +71,7 @@ func TestScheduler_ComputeSchedule_SharedVesselOverridesRouteSchedule(t *testing
 		SetTurnaroundDelay(5 * time.Minute).
 		Build()
 
-	scheduler := NewScheduler([]Model{routeA, routeB, independentRoute}, []SharedVesselModel{sharedVessel})
+	scheduler := NewScheduler(clock.Fixed(fixedTime), []Model{routeA, routeB, independentRoute}, []SharedVesselModel{sharedVessel})
 	schedules := scheduler.ComputeSchedule()
 
 	routeCounts := schedulesPerRoute(schedules)
@@ -93,3 +91,36 @@ func TestScheduler_ComputeSchedule_SharedVesselOverridesRouteSchedule(t *testing
 	// Confirm shared routes do not have independent periodic schedules, only shared trips
 	assert.True(t, totalSharedTrips > 0 && totalSharedTrips < 96, "Total shared trips should be reasonable (i.e., no periodic schedules added)")
 }
+
+func TestScheduler_ComputeSchedule_DeterministicTripIds(t *testing.T) {
+	fixedTime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	route := NewBuilder("Route A").
+		SetId(uuid.MustParse("11111111-1111-1111-1111-111111111111")).
+		SetBoardingWindowDuration(5 * time.Minute).
+		SetPreDepartureDuration(2 * time.Minute).
+		SetTravelDuration(10 * time.Minute).
+		SetCycleInterval(30 * time.Minute).
+		Build()
+
+	first := NewScheduler(clock.Fixed(fixedTime), []Model{route}, nil).ComputeSchedule()
+	second := NewScheduler(clock.Fixed(fixedTime.Add(24*time.Hour)), []Model{route}, nil).ComputeSchedule()
+
+	assert.Equal(t, len(first), len(second))
+	for i := range first {
+		assert.Equal(t, first[i].TripId(), second[i].TripId(), "Trip ids should be stable across recomputation")
+	}
+	assert.NotEqual(t, first[0].TripId(), first[1].TripId(), "Trips should have distinct ids")
+}
+
+func TestScheduler_ComputeSchedule_SkipsRoutesWithoutCycle(t *testing.T) {
+	fixedTime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	route := NewBuilder("No Cycle").
+		SetBoardingWindowDuration(5 * time.Minute).
+		SetTravelDuration(10 * time.Minute).
+		SetCycleInterval(0).
+		Build()
+
+	assert.Empty(t, NewScheduler(clock.Fixed(fixedTime), []Model{route}, nil).ComputeSchedule())
+}
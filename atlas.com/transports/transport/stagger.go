@@ -0,0 +1,112 @@
+package transport
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Chronicle20/atlas-constants/channel"
+	"github.com/Chronicle20/atlas-constants/world"
+)
+
+// phaseStep is a single side effect of a transition on a staggered channel: either the warps of a leg, or a status
+// event.
+type phaseStep struct {
+	tripOccurrenceId string
+	leg              Leg
+	movements        []movement
+	event            string
+}
+
+// channelPhase is the share of a transition's side effects which a staggered channel receives once its offset has
+// elapsed, in the order they were buffered.
+type channelPhase struct {
+	channel channel.Model
+	offset  time.Duration
+	steps   []phaseStep
+}
+
+// Channel returns the channel the phase is issued to
+func (m channelPhase) Channel() channel.Model {
+	return m.channel
+}
+
+// Offset returns how long after the transition the phase is issued
+func (m channelPhase) Offset() time.Duration {
+	return m.offset
+}
+
+// staggerPlan collects the channel phases of a transition while its side effects are buffered, so that they are only
+// scheduled once the transition commits. Each channel receives a single phase per transition.
+type staggerPlan struct {
+	mu     sync.Mutex
+	phases map[channel.Model]*channelPhase
+}
+
+func newStaggerPlan() *staggerPlan {
+	return &staggerPlan{phases: make(map[channel.Model]*channelPhase)}
+}
+
+func (s *staggerPlan) phase(c channel.Model, offset time.Duration) *channelPhase {
+	cp, ok := s.phases[c]
+	if !ok {
+		cp = &channelPhase{channel: c, offset: offset}
+		s.phases[c] = cp
+	}
+	return cp
+}
+
+// addWarp adds the movement to the warps of the leg on the channel.
+func (s *staggerPlan) addWarp(c channel.Model, offset time.Duration, tripOccurrenceId string, leg Leg, m movement) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cp := s.phase(c, offset)
+	if n := len(cp.steps); n > 0 && cp.steps[n-1].event == "" && cp.steps[n-1].leg == leg && cp.steps[n-1].tripOccurrenceId == tripOccurrenceId {
+		cp.steps[n-1].movements = append(cp.steps[n-1].movements, m)
+		return
+	}
+	cp.steps = append(cp.steps, phaseStep{tripOccurrenceId: tripOccurrenceId, leg: leg, movements: []movement{m}})
+}
+
+// addEvent adds a status event of the given type on the channel.
+func (s *staggerPlan) addEvent(c channel.Model, offset time.Duration, eventType string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cp := s.phase(c, offset)
+	cp.steps = append(cp.steps, phaseStep{event: eventType})
+}
+
+// Phases returns the collected phases, ordered by offset.
+func (s *staggerPlan) Phases() []channelPhase {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	results := make([]channelPhase, 0, len(s.phases))
+	for _, cp := range s.phases {
+		results = append(results, *cp)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].offset < results[j].offset
+	})
+	return results
+}
+
+// channelOffsets assigns each channel of a staggered route its phase offset. Channels are ranked by id within their
+// world, so the offsets of a world's channels are consecutive multiples of the stagger however sparse their ids are.
+func channelOffsets(r Model, channels []channel.Model) map[channel.Model]time.Duration {
+	offsets := make(map[channel.Model]time.Duration)
+	byWorld := make(map[world.Id][]channel.Model)
+	for _, c := range channels {
+		if r.EnabledIn(c.WorldId(), c.Id()) {
+			byWorld[c.WorldId()] = append(byWorld[c.WorldId()], c)
+		}
+	}
+	for _, cs := range byWorld {
+		sort.Slice(cs, func(i, j int) bool {
+			return cs[i].Id() < cs[j].Id()
+		})
+		for rank, c := range cs {
+			offsets[c] = r.ChannelOffset(rank)
+		}
+	}
+	return offsets
+}
@@ -0,0 +1,178 @@
+package transport
+
+import (
+	"atlas-transports/channel"
+	"atlas-transports/clock"
+	"atlas-transports/kafka/message"
+	character2 "atlas-transports/kafka/message/character"
+	transport2 "atlas-transports/kafka/message/transport"
+	"atlas-transports/occupancy"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	channel2 "github.com/Chronicle20/atlas-constants/channel"
+	"github.com/Chronicle20/atlas-constants/field"
+	"github.com/Chronicle20/atlas-kafka/producer"
+	"github.com/Chronicle20/atlas-model/model"
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubChannels struct {
+	channel.Processor
+	channels []channel2.Model
+}
+
+func (s stubChannels) GetAll() []channel2.Model {
+	return s.channels
+}
+
+type stubOccupancy struct {
+	occupancy.Processor
+	occupants map[field.Id][]uint32
+}
+
+func (s stubOccupancy) CharacterIdsInFieldsProvider(fields []field.Model) model.Provider[map[field.Id][]uint32] {
+	results := make(map[field.Id][]uint32)
+	for _, f := range fields {
+		results[f.Id()] = s.occupants[f.Id()]
+	}
+	return model.FixedProvider(results)
+}
+
+func TestChannelOffsets_RankChannelsWithinWorld(t *testing.T) {
+	route := NewBuilder("Staggered").
+		SetChannelStagger(250 * time.Millisecond).
+		SetEnablement([]WorldEnablementModel{NewWorldEnablementModel(0, []channel2.Id{3, 9, 17}), NewWorldEnablementModel(1, nil)}).
+		Build()
+	channels := []channel2.Model{
+		channel2.NewModel(0, 17), channel2.NewModel(0, 3), channel2.NewModel(0, 9), channel2.NewModel(0, 4),
+		channel2.NewModel(1, 12),
+	}
+
+	offsets := channelOffsets(route, channels)
+	assert.Equal(t, map[channel2.Model]time.Duration{
+		channel2.NewModel(0, 3):  0,
+		channel2.NewModel(0, 9):  250 * time.Millisecond,
+		channel2.NewModel(0, 17): 500 * time.Millisecond,
+		channel2.NewModel(1, 12): 0,
+	}, offsets)
+}
+
+func TestTransition_CollectsStaggeredChannelsForLater(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	route := reconcileRoute(start).Builder().
+		SetDepartureWarp(NewWarpStrategy(WarpFixedPortal, "", 1)).
+		SetChannelStagger(250 * time.Millisecond).
+		Build()
+
+	l, _ := test.NewNullLogger()
+	ten, _ := tenant.Register(uuid.New(), "GMS", 83, 1)
+	p := newProcessor(l, tenant.WithContext(context.Background(), ten))
+	p.chanP = stubChannels{channels: []channel2.Model{channel2.NewModel(0, 0), channel2.NewModel(0, 1), channel2.NewModel(0, 5), channel2.NewModel(1, 2)}}
+	p.occP = stubOccupancy{occupants: map[field.Id][]uint32{
+		field.NewBuilder(0, 0, 101).Build().Id(): {1},
+		field.NewBuilder(0, 5, 101).Build().Id(): {2},
+		field.NewBuilder(1, 2, 101).Build().Id(): {3},
+	}}
+
+	// The 12:00 trip departs at 12:07
+	from, _ := route.UpdateState(start.Add(12*time.Hour + 6*time.Minute))
+	now := start.Add(12*time.Hour + 7*time.Minute + time.Second)
+	p.clk = clock.Fixed(now)
+	to, _ := from.UpdateState(now)
+	assert.Equal(t, InTransit, to.State())
+
+	published := make(map[string]int)
+	p.p = func(token string) producer.MessageProducer {
+		return func(provider model.Provider[[]kafka.Message]) error {
+			ms, err := provider()
+			published[token] += len(ms)
+			return err
+		}
+	}
+	assert.NoError(t, message.Emit(p.warpProducer())(func(mb *message.Buffer) error {
+		return p.Transition(mb)(from, to, now)
+	}))
+	assert.Equal(t, 2, published[character2.EnvCommandTopic])
+	assert.Equal(t, 2, published[transport2.EnvEventTopicStatus])
+	assert.Equal(t, int32(2), p.moved.Load())
+
+	phases := p.stagger.Phases()
+	if assert.Len(t, phases, 2) {
+		assert.Equal(t, channel2.NewModel(0, 1), phases[0].Channel())
+		assert.Equal(t, 250*time.Millisecond, phases[0].Offset())
+		assert.Equal(t, channel2.NewModel(0, 5), phases[1].Channel())
+		assert.Equal(t, 500*time.Millisecond, phases[1].Offset())
+		assert.Len(t, phases[1].steps, 2)
+		assert.Equal(t, LegDeparture, phases[1].steps[0].leg)
+		assert.Equal(t, transport2.EventStatusDeparted, phases[1].steps[1].event)
+
+		published = make(map[string]int)
+		assert.NoError(t, message.Emit(p.warpProducer())(func(mb *message.Buffer) error {
+			return p.issuePhase(mb)(to, phases[1])
+		}))
+		assert.Equal(t, 1, published[character2.EnvCommandTopic])
+		assert.Equal(t, 1, published[transport2.EnvEventTopicStatus])
+		assert.Equal(t, int32(3), p.moved.Load())
+	}
+}
+
+func TestRecoverable_JudgesStaggeredChannelsByStateLastSent(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	route := reconcileRoute(start).Builder().SetChannelStagger(20 * time.Second).Build()
+	now := start.Add(12*time.Hour + 7*time.Minute + 35*time.Second)
+	p := reconcileProcessor(route, now)
+	p.chanP = stubChannels{channels: []channel2.Model{channel2.NewModel(0, 0), channel2.NewModel(0, 1), channel2.NewModel(0, 2)}}
+	committed, _ := route.UpdateState(now)
+	assert.Equal(t, InTransit, committed.State())
+
+	// Channel 0 departed 35 seconds ago
+	view, ok := p.recoverable(committed, 0, 0, now)
+	assert.True(t, ok)
+	assert.Equal(t, InTransit, view.State())
+
+	// Channel 1 departed 15 seconds ago, within the grace period
+	_, ok = p.recoverable(committed, 0, 1, now)
+	assert.False(t, ok)
+
+	// Channel 2 has yet to depart, and is still judged as boarding
+	view, ok = p.recoverable(committed, 0, 2, now)
+	assert.True(t, ok)
+	assert.Equal(t, LockedEntry, view.State())
+}
+
+func TestSnapshot_DescribesStaggeredChannelsByStateLastSent(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	route := reconcileRoute(start).Builder().SetChannelStagger(20 * time.Second).Build()
+	now := start.Add(12*time.Hour + 7*time.Minute + 10*time.Second)
+	p := reconcileProcessor(route, now)
+	p.chanP = stubChannels{channels: []channel2.Model{channel2.NewModel(0, 0), channel2.NewModel(0, 1), channel2.NewModel(0, 2)}}
+	committed, _ := route.UpdateState(now)
+
+	snapshot := func(channelId channel2.Id) transport2.SnapshotStatusEventBody {
+		mb := message.NewBuffer()
+		assert.NoError(t, p.snapshot(mb)(0, channelId)(committed))
+		ms := mb.GetAll()[transport2.EnvEventTopicStatus]
+		assert.Len(t, ms, 1)
+		var e transport2.StatusEvent[transport2.SnapshotStatusEventBody]
+		assert.NoError(t, json.Unmarshal(ms[0].Value, &e))
+		return e.Body
+	}
+
+	// Channel 0 departed 10 seconds ago, and arrives at 12:17
+	body := snapshot(0)
+	assert.Equal(t, string(InTransit), body.State)
+	assert.Equal(t, (9*time.Minute + 50*time.Second).Milliseconds(), body.RemainingMillis)
+
+	// Channel 2 departs once its 40 second offset has elapsed
+	body = snapshot(2)
+	assert.Equal(t, string(LockedEntry), body.State)
+	assert.Equal(t, (30 * time.Second).Milliseconds(), body.RemainingMillis)
+	assert.True(t, now.Add(30*time.Second).Equal(body.NextTransitionAt))
+}
@@ -1,5 +1,7 @@
 package transport
 
+import "time"
+
 // RouteState represents the state of a transport route
 type RouteState string
 
@@ -19,3 +21,101 @@ const (
 	// InTransit indicates that characters are in the en-route map
 	InTransit RouteState = "in_transit"
 )
+
+// routeStates is every state a route can be in.
+var routeStates = []RouteState{OutOfService, AwaitingReturn, OpenEntry, LockedEntry, InTransit}
+
+// cycle is the order in which a route in service moves through its states.
+var cycle = []RouteState{AwaitingReturn, OpenEntry, LockedEntry, InTransit}
+
+// transitionPath returns every state entered when moving from one state to another, in order and ending with the
+// target state. More than one state means intermediate transitions were skipped, for example because the update loop
+// stalled, and their side effects must be replayed.
+func transitionPath(from RouteState, to RouteState) []RouteState {
+	if from == to || from == OutOfService {
+		return []RouteState{to}
+	}
+	if to == OutOfService {
+		if from == OpenEntry || from == LockedEntry {
+			return append(transitionPath(from, InTransit), OutOfService)
+		}
+		return []RouteState{OutOfService}
+	}
+
+	start := -1
+	for i, s := range cycle {
+		if s == from {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return []RouteState{to}
+	}
+
+	var path []RouteState
+	for i := 1; i <= len(cycle); i++ {
+		s := cycle[(start+i)%len(cycle)]
+		path = append(path, s)
+		if s == to {
+			break
+		}
+	}
+	return path
+}
+
+// replayHorizon bounds how far back the schedule is walked to work out the states a stalled route skipped.
+const replayHorizon = 25 * time.Hour
+
+// replayPath returns every state entered by the route since its state was last evaluated, in order and ending with its
+// new state, along with the state the path starts from. The path is walked from the schedule, so that a stall of a
+// full cycle or more, which leaves the route in the state it was in, still replays the trip it missed. Only the last
+// cycle of the path is replayed. When the route was not evaluated before, or the clock moved backwards, the path is
+// inferred from the states alone.
+func replayPath(from Model, to Model, now time.Time) (RouteState, []RouteState) {
+	states := walkStates(from, now)
+	if len(states) == 1 || states[len(states)-1] != to.State() {
+		return from.State(), transitionPath(from.State(), to.State())
+	}
+	if len(states) > len(cycle)+1 {
+		states = states[len(states)-len(cycle)-1:]
+	}
+	return states[0], states[1:]
+}
+
+// skippedCycle reports whether the route moved through its states since it was last evaluated and came back to the
+// state it was in, such as when the update loop stalls for a full cycle. Comparing states alone would miss the trip.
+func skippedCycle(from Model, now time.Time) bool {
+	states := walkStates(from, now)
+	return len(states) > 1 && states[len(states)-1] == from.State()
+}
+
+// walkStates returns the state of the route when it was last evaluated, followed by every state the schedule moved it
+// to since, up to now. Only the state it was last evaluated in is returned when it was not evaluated before, or the
+// clock moved backwards.
+func walkStates(from Model, now time.Time) []RouteState {
+	states := []RouteState{from.State()}
+	since := from.UpdatedAt()
+	if since.IsZero() || !since.Before(now) {
+		return states
+	}
+	if now.Sub(since) > replayHorizon {
+		since = now.Add(-replayHorizon)
+	}
+	for at := since; ; {
+		next, ok := from.NextTransitionAt(at)
+		if !ok || next.After(now) {
+			break
+		}
+		// Sample just past the transition, as the state machine's boundaries are exclusive.
+		sample := next.Add(time.Millisecond)
+		if sample.After(now) {
+			sample = now
+		}
+		if state := from.processStateChange(sample); state != states[len(states)-1] {
+			states = append(states, state)
+		}
+		at = next
+	}
+	return states
+}
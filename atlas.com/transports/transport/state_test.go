@@ -1,6 +1,7 @@
 package transport
 
 import (
+	"atlas-transports/clock"
 	"testing"
 	"time"
 
@@ -396,3 +397,124 @@ func TestStateMachine_StateChanged(t *testing.T) {
 		})
 	}
 }
+
+func TestTransitionPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     RouteState
+		to       RouteState
+		expected []RouteState
+	}{
+		{"Initial state", OutOfService, OpenEntry, []RouteState{OpenEntry}},
+		{"Single step", LockedEntry, InTransit, []RouteState{InTransit}},
+		{"Skipped departure", OpenEntry, AwaitingReturn, []RouteState{LockedEntry, InTransit, AwaitingReturn}},
+		{"Wraps around the cycle", InTransit, LockedEntry, []RouteState{AwaitingReturn, OpenEntry, LockedEntry}},
+		{"Last trip of the day arrives", InTransit, OutOfService, []RouteState{OutOfService}},
+		{"Skipped last departure of the day", OpenEntry, OutOfService, []RouteState{LockedEntry, InTransit, OutOfService}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, transitionPath(tt.from, tt.to))
+		})
+	}
+}
+
+func TestModel_NextTransitionAt(t *testing.T) {
+	now := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	routeID := uuid.New()
+	trip := NewTripScheduleBuilder().
+		SetTripId(uuid.New()).
+		SetRouteId(routeID).
+		SetBoardingOpen(now.Add(-5 * time.Minute)).
+		SetBoardingClosed(now.Add(3 * time.Minute)).
+		SetDeparture(now.Add(5 * time.Minute)).
+		SetArrival(now.Add(15 * time.Minute)).
+		Build()
+	route := NewBuilder("Test Route").
+		SetId(routeID).
+		SetSchedule([]TripScheduleModel{trip}).
+		Build()
+
+	next, ok := route.NextTransitionAt(now)
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(3*time.Minute), next)
+
+	// After the last transition of the day, the next is the first of the following day
+	next, ok = route.NextTransitionAt(now.Add(20 * time.Minute))
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(-5*time.Minute).AddDate(0, 0, 1), next)
+
+	_, ok = NewBuilder("Empty Route").SetId(uuid.New()).Build().NextTransitionAt(now)
+	assert.False(t, ok)
+}
+
+func TestModel_LastDepartureIgnoresClockLocation(t *testing.T) {
+	routeID := uuid.New()
+	departure := time.Date(2023, 1, 1, 23, 30, 0, 0, time.UTC)
+	trip := NewTripScheduleBuilder().
+		SetTripId(uuid.New()).
+		SetRouteId(routeID).
+		SetBoardingOpen(departure.Add(-10 * time.Minute)).
+		SetBoardingClosed(departure.Add(-5 * time.Minute)).
+		SetDeparture(departure).
+		SetArrival(departure.Add(20 * time.Minute)).
+		Build()
+	route := NewBuilder("Test Route").
+		SetId(routeID).
+		SetSchedule([]TripScheduleModel{trip}).
+		Build()
+
+	// The same instant read in a zone where it is already the following day
+	now := time.Date(2023, 1, 2, 23, 40, 0, 0, time.UTC)
+	seoul := time.FixedZone("KST", 9*60*60)
+	_, utcDeparted, ok := route.LastDeparture(now)
+	assert.True(t, ok)
+	_, seoulDeparted, ok := route.LastDeparture(now.In(seoul))
+	assert.True(t, ok)
+	assert.True(t, utcDeparted.Equal(seoulDeparted))
+	assert.Equal(t, TripOccurrenceId(trip.TripId(), utcDeparted), TripOccurrenceId(trip.TripId(), seoulDeparted))
+	assert.Equal(t, route.processStateChange(now), route.processStateChange(now.In(seoul)))
+}
+
+func TestReplayPath_FullCycleStall(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	route := NewBuilder("Test Route").
+		SetId(uuid.New()).
+		SetStartMapId(100).
+		SetStagingMapId(101).
+		SetEnRouteMapIds([]_map.Id{102}).
+		SetDestinationMapId(103).
+		SetBoardingWindowDuration(5 * time.Minute).
+		SetPreDepartureDuration(2 * time.Minute).
+		SetTravelDuration(10 * time.Minute).
+		SetCycleInterval(30 * time.Minute).
+		Build()
+	route = ScheduleRoutes(clock.Fixed(start), []Model{route}, nil)[0]
+
+	// Evaluated while boarding the 12:00 trip, then stalled until boarding the 12:30 trip
+	since := start.Add(12*time.Hour + time.Minute)
+	from, _ := route.UpdateState(since)
+	assert.Equal(t, OpenEntry, from.State())
+	now := since.Add(30 * time.Minute)
+	to, changed := from.UpdateState(now)
+	assert.False(t, changed)
+	assert.True(t, skippedCycle(from, now))
+
+	previous, path := replayPath(from, to, now)
+	assert.Equal(t, OpenEntry, previous)
+	assert.Equal(t, []RouteState{LockedEntry, InTransit, AwaitingReturn, OpenEntry}, path)
+
+	// A tick later, nothing was skipped
+	assert.False(t, skippedCycle(from, since.Add(time.Second)))
+
+	// Only the last cycle of a longer stall is replayed
+	previous, path = replayPath(from, to, now.Add(2*time.Hour))
+	assert.Equal(t, OpenEntry, previous)
+	assert.Equal(t, []RouteState{LockedEntry, InTransit, AwaitingReturn, OpenEntry}, path)
+
+	// Without a previous evaluation, the path is inferred from the states
+	previous, path = replayPath(route.Builder().SetState(OpenEntry).Build(), to, now)
+	assert.Equal(t, OpenEntry, previous)
+	assert.Equal(t, []RouteState{OpenEntry}, path)
+}
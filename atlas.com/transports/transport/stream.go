@@ -0,0 +1,124 @@
+package transport
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	StreamEventSnapshot   = "snapshot"
+	StreamEventTransition = "transition"
+
+	// streamHeartbeatInterval is how often an idle stream sends a comment, keeping proxies from closing it.
+	streamHeartbeatInterval = 15 * time.Second
+)
+
+// StreamRouteStateRestModel is the state of a route within a stream snapshot
+type StreamRouteStateRestModel struct {
+	RouteId          uuid.UUID  `json:"routeId"`
+	Name             string     `json:"name"`
+	State            string     `json:"state"`
+	NextTransitionAt *time.Time `json:"nextTransitionAt,omitempty"`
+}
+
+// StreamSnapshotRestModel is the payload of a snapshot event, holding the state of every route of the tenant
+type StreamSnapshotRestModel struct {
+	Routes []StreamRouteStateRestModel `json:"routes"`
+}
+
+// StreamTransitionRestModel is the payload of a transition event. A transition which replays skipped states publishes
+// an event per state entered, of which only the last carries the scheduled time and the next transition.
+type StreamTransitionRestModel struct {
+	RouteId          uuid.UUID  `json:"routeId"`
+	Name             string     `json:"name"`
+	FromState        string     `json:"fromState"`
+	ToState          string     `json:"toState"`
+	ScheduledAt      *time.Time `json:"scheduledAt,omitempty"`
+	OccurredAt       time.Time  `json:"occurredAt"`
+	TripOccurrenceId string     `json:"tripOccurrenceId,omitempty"`
+	NextTransitionAt *time.Time `json:"nextTransitionAt,omitempty"`
+}
+
+func nextTransitionAt(r Model, now time.Time) *time.Time {
+	if next, ok := r.NextTransitionAt(now); ok {
+		return &next
+	}
+	return nil
+}
+
+// TransformStreamRouteState converts the state of a route, as of now, to a StreamRouteStateRestModel
+func TransformStreamRouteState(now time.Time) func(r Model) (StreamRouteStateRestModel, error) {
+	return func(r Model) (StreamRouteStateRestModel, error) {
+		return StreamRouteStateRestModel{
+			RouteId:          r.Id(),
+			Name:             r.Name(),
+			State:            string(r.State()),
+			NextTransitionAt: nextTransitionAt(r, now),
+		}, nil
+	}
+}
+
+// enteredState is a state a transition entered, in the order the transition entered them.
+type enteredState struct {
+	from RouteState
+	to   RouteState
+}
+
+// stateLog collects the states a transition entered once their side effects are published, so they can be streamed
+// when the transition commits.
+type stateLog struct {
+	mu      sync.Mutex
+	entered []enteredState
+}
+
+func (l *stateLog) add(from RouteState, to RouteState) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entered = append(l.entered, enteredState{from: from, to: to})
+}
+
+// Entered returns the states entered, in order
+func (l *stateLog) Entered() []enteredState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]enteredState(nil), l.entered...)
+}
+
+// TransformStreamTransitions converts the states entered by a committed transition of a route to a
+// StreamTransitionRestModel each. The record describes the transition as a whole, so its scheduled time and the next
+// transition are given with the last state.
+func TransformStreamTransitions(r Model, m TransitionRecordModel, entered []enteredState) []interface{} {
+	results := make([]interface{}, 0, len(entered))
+	for i, e := range entered {
+		rm := StreamTransitionRestModel{
+			RouteId:          r.Id(),
+			Name:             r.Name(),
+			FromState:        string(e.from),
+			ToState:          string(e.to),
+			OccurredAt:       m.OccurredAt(),
+			TripOccurrenceId: m.TripOccurrenceId(),
+		}
+		if i == len(entered)-1 {
+			scheduledAt := m.ScheduledAt()
+			rm.ScheduledAt = &scheduledAt
+			rm.NextTransitionAt = nextTransitionAt(r, m.OccurredAt())
+		}
+		results = append(results, rm)
+	}
+	return results
+}
+
+// writeStreamEvent writes the event in the Server-Sent Events format.
+func writeStreamEvent(w io.Writer, e StreamEventModel) error {
+	data, err := json.Marshal(e.Data())
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.Id(), e.Name(), data)
+	return err
+}
@@ -0,0 +1,148 @@
+package transport
+
+import (
+	"sync"
+
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/google/uuid"
+)
+
+const (
+	// streamBacklog is the number of recent events retained per tenant for subscribers resuming from an event id.
+	streamBacklog = 512
+	// streamSubscriberBuffer is the number of events a subscriber may fall behind by before it is disconnected.
+	streamSubscriberBuffer = 64
+)
+
+// StreamEventModel is a route event published to the live stream of a tenant.
+type StreamEventModel struct {
+	id   uint64
+	name string
+	data interface{}
+}
+
+// Id returns the sequence number of the event within the tenant's stream
+func (e StreamEventModel) Id() uint64 {
+	return e.id
+}
+
+// Name returns the type of the event
+func (e StreamEventModel) Name() string {
+	return e.name
+}
+
+// Data returns the payload of the event
+func (e StreamEventModel) Data() interface{} {
+	return e.data
+}
+
+type tenantStream struct {
+	sequence    uint64
+	backlog     []StreamEventModel
+	subscribers map[chan StreamEventModel]struct{}
+}
+
+// StreamRegistry fans route events of each tenant out to live subscribers, and retains recent events so subscribers
+// can resume after reconnecting.
+type StreamRegistry struct {
+	mutex   sync.Mutex
+	streams map[uuid.UUID]*tenantStream
+}
+
+var streamRegistry *StreamRegistry
+var streamRegistryOnce sync.Once
+
+func getStreamRegistry() *StreamRegistry {
+	streamRegistryOnce.Do(func() {
+		streamRegistry = &StreamRegistry{}
+		streamRegistry.streams = make(map[uuid.UUID]*tenantStream)
+	})
+	return streamRegistry
+}
+
+func (r *StreamRegistry) stream(t tenant.Model) *tenantStream {
+	s, ok := r.streams[t.Id()]
+	if !ok {
+		s = &tenantStream{subscribers: make(map[chan StreamEventModel]struct{})}
+		r.streams[t.Id()] = s
+	}
+	return s
+}
+
+// Publish assigns the event the next sequence number of the tenant's stream and delivers it to every subscriber.
+// Subscribers which have fallen too far behind are disconnected, and may resume from the backlog.
+func (r *StreamRegistry) Publish(t tenant.Model, name string, data interface{}) StreamEventModel {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.stream(t).publish(name, data)
+}
+
+// Commit applies a change to the state snapshots are built from, and publishes the events describing it, as a single
+// step. Snapshots are built under the same lock, so a subscriber never receives an event its snapshot already reflects,
+// nor misses one it does not. Nothing is published when the change fails.
+func (r *StreamRegistry) Commit(t tenant.Model, change func() error, name string, data ...interface{}) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if err := change(); err != nil {
+		return err
+	}
+	s := r.stream(t)
+	for _, d := range data {
+		s.publish(name, d)
+	}
+	return nil
+}
+
+func (s *tenantStream) publish(name string, data interface{}) StreamEventModel {
+	s.sequence++
+	e := StreamEventModel{id: s.sequence, name: name, data: data}
+	s.backlog = append(s.backlog, e)
+	if len(s.backlog) > streamBacklog {
+		s.backlog = append([]StreamEventModel(nil), s.backlog[len(s.backlog)-streamBacklog:]...)
+	}
+	for ch := range s.subscribers {
+		select {
+		case ch <- e:
+		default:
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+	}
+	return e
+}
+
+// Subscribe registers a subscriber to the tenant's stream. When lastEventId can be resumed from, the events after it
+// are returned for replay. Otherwise, resumed is false and the replay is a snapshot event, built by snapshot while the
+// stream is locked so that it reflects state as of the returned sequence number. The returned function unsubscribes.
+func (r *StreamRegistry) Subscribe(t tenant.Model, lastEventId uint64, resume bool, snapshot func() interface{}) (<-chan StreamEventModel, []StreamEventModel, uint64, bool, func()) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	s := r.stream(t)
+	ch := make(chan StreamEventModel, streamSubscriberBuffer)
+	s.subscribers[ch] = struct{}{}
+	unsubscribe := func() {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		if _, ok := s.subscribers[ch]; ok {
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+	}
+
+	if !resume || lastEventId > s.sequence {
+		return ch, []StreamEventModel{{id: s.sequence, name: StreamEventSnapshot, data: snapshot()}}, s.sequence, false, unsubscribe
+	}
+	if lastEventId == s.sequence {
+		return ch, nil, s.sequence, true, unsubscribe
+	}
+	if len(s.backlog) == 0 || s.backlog[0].Id() > lastEventId+1 {
+		return ch, []StreamEventModel{{id: s.sequence, name: StreamEventSnapshot, data: snapshot()}}, s.sequence, false, unsubscribe
+	}
+	replay := make([]StreamEventModel, 0)
+	for _, e := range s.backlog {
+		if e.Id() > lastEventId {
+			replay = append(replay, e)
+		}
+	}
+	return ch, replay, s.sequence, true, unsubscribe
+}
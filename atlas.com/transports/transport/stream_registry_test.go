@@ -0,0 +1,83 @@
+package transport
+
+import (
+	"errors"
+	"testing"
+
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func emptySnapshot() interface{} {
+	return StreamSnapshotRestModel{Routes: make([]StreamRouteStateRestModel, 0)}
+}
+
+func TestStreamRegistry_Resume(t *testing.T) {
+	ten, _ := tenant.Register(uuid.New(), "GMS", 83, 1)
+	r := getStreamRegistry()
+
+	for i := 0; i < 3; i++ {
+		r.Publish(ten, StreamEventTransition, i)
+	}
+
+	_, replay, sequence, resumed, unsubscribe := r.Subscribe(ten, 1, true, emptySnapshot)
+	defer unsubscribe()
+	assert.True(t, resumed)
+	assert.Equal(t, uint64(3), sequence)
+	assert.Len(t, replay, 2)
+	assert.Equal(t, uint64(2), replay[0].Id())
+
+	// Ids beyond the stream, for example from before a restart, need a snapshot.
+	_, _, _, resumed, unsubscribe = r.Subscribe(ten, 10, true, emptySnapshot)
+	defer unsubscribe()
+	assert.False(t, resumed)
+}
+
+func TestStreamRegistry_ResumeBeyondBacklog(t *testing.T) {
+	ten, _ := tenant.Register(uuid.New(), "GMS", 83, 1)
+	r := getStreamRegistry()
+
+	for i := 0; i < streamBacklog+10; i++ {
+		r.Publish(ten, StreamEventTransition, i)
+	}
+
+	_, _, _, resumed, unsubscribe := r.Subscribe(ten, 5, true, emptySnapshot)
+	defer unsubscribe()
+	assert.False(t, resumed)
+}
+
+func TestStreamRegistry_DisconnectsSlowSubscribers(t *testing.T) {
+	ten, _ := tenant.Register(uuid.New(), "GMS", 83, 1)
+	r := getStreamRegistry()
+
+	events, _, _, _, unsubscribe := r.Subscribe(ten, 0, false, emptySnapshot)
+	defer unsubscribe()
+	for i := 0; i < streamSubscriberBuffer+1; i++ {
+		r.Publish(ten, StreamEventTransition, i)
+	}
+
+	received := 0
+	for range events {
+		received++
+	}
+	assert.Equal(t, streamSubscriberBuffer, received)
+}
+
+func TestStreamRegistry_CommitPublishesOnlyCommittedChanges(t *testing.T) {
+	ten, _ := tenant.Register(uuid.New(), "GMS", 83, 1)
+	r := getStreamRegistry()
+
+	err := r.Commit(ten, func() error { return errors.New("route not found") }, StreamEventTransition, 1)
+	assert.Error(t, err)
+	assert.NoError(t, r.Commit(ten, func() error { return nil }, StreamEventTransition, 1, 2))
+
+	_, replay, sequence, resumed, unsubscribe := r.Subscribe(ten, 0, false, emptySnapshot)
+	defer unsubscribe()
+	assert.False(t, resumed)
+	assert.Equal(t, uint64(2), sequence)
+	if assert.Len(t, replay, 1) {
+		assert.Equal(t, StreamEventSnapshot, replay[0].Name())
+		assert.Equal(t, uint64(2), replay[0].Id())
+	}
+}
@@ -0,0 +1,36 @@
+package transport
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// timezoneFor resolves the timezone schedules of the tenant are published in. TENANT_TIMEZONES holds IANA zone names
+// as a JSON object keyed by tenant id, for example {"083839c6-c47c-42a6-9585-76492795d123":"Europe/Berlin"}. Tenants
+// without a valid zone use the server's local time, and a malformed variable or unknown zone is logged. The schedule
+// itself runs in UTC.
+func timezoneFor(l logrus.FieldLogger, tenantId uuid.UUID) *time.Location {
+	val, ok := os.LookupEnv("TENANT_TIMEZONES")
+	if !ok {
+		return time.Local
+	}
+	var zones map[string]string
+	if err := json.Unmarshal([]byte(val), &zones); err != nil {
+		l.WithError(err).Errorf("Unable to parse TENANT_TIMEZONES, publishing schedules in server local time.")
+		return time.Local
+	}
+	name, ok := zones[tenantId.String()]
+	if !ok {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		l.WithError(err).Errorf("Unknown timezone [%s] for tenant [%s] in TENANT_TIMEZONES, publishing schedules in server local time.", name, tenantId)
+		return time.Local
+	}
+	return loc
+}
@@ -0,0 +1,269 @@
+package transport
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// QuarantinedRoute is the kind of a quarantined route definition.
+	QuarantinedRoute = "routes"
+	// QuarantinedVessel is the kind of a quarantined shared vessel definition.
+	QuarantinedVessel = "vessels"
+)
+
+// maxChannelsPerWorld is the number of channels a world enabled as a whole is assumed to run when validating a route's
+// channel stagger.
+const maxChannelsPerWorld = 20
+
+// FieldErrorModel is a problem with a single field of a definition.
+type FieldErrorModel struct {
+	field   string
+	message string
+}
+
+// Field returns the name of the field, as in the configuration service's representation
+func (m FieldErrorModel) Field() string {
+	return m.field
+}
+
+// Message returns what is wrong with the field
+func (m FieldErrorModel) Message() string {
+	return m.message
+}
+
+// QuarantineModel is a route or shared vessel definition which failed validation, and is not scheduled.
+type QuarantineModel struct {
+	kind   string
+	id     uuid.UUID
+	name   string
+	errors []FieldErrorModel
+}
+
+// Kind returns whether the definition is of a route or a shared vessel
+func (m QuarantineModel) Kind() string {
+	return m.kind
+}
+
+// Id returns the ID of the definition
+func (m QuarantineModel) Id() uuid.UUID {
+	return m.id
+}
+
+// Name returns the name of the definition
+func (m QuarantineModel) Name() string {
+	return m.name
+}
+
+// Errors returns the problems found with the fields of the definition
+func (m QuarantineModel) Errors() []FieldErrorModel {
+	return m.errors
+}
+
+// ValidateConfiguration checks route and shared vessel definitions before they are scheduled. Definitions which would
+// misbehave, such as by never producing a trip or by scheduling forever, are left out of the results and quarantined
+// with the errors found. A vessel serving a quarantined route is quarantined as well. A route whose vessel is
+// quarantined runs on its own cycle, so is held to the rules of unshared routes.
+func ValidateConfiguration(routes []Model, vessels []SharedVesselModel) ([]Model, []SharedVesselModel, []QuarantineModel) {
+	quarantined := make([]QuarantineModel, 0)
+
+	candidates := make([]Model, 0, len(routes))
+	byId := make(map[uuid.UUID]Model)
+	invalid := make(map[uuid.UUID]bool)
+	for _, r := range routes {
+		errs := validateRoute(r)
+		if _, ok := byId[r.Id()]; ok && r.Id() != uuid.Nil {
+			errs = append(errs, FieldErrorModel{"id", "is used by another route"})
+		}
+		if len(errs) > 0 {
+			quarantined = append(quarantined, QuarantineModel{kind: QuarantinedRoute, id: r.Id(), name: r.Name(), errors: errs})
+			if _, ok := byId[r.Id()]; !ok {
+				invalid[r.Id()] = true
+			}
+			continue
+		}
+		byId[r.Id()] = r
+		candidates = append(candidates, r)
+	}
+
+	validVessels := make([]SharedVesselModel, 0, len(vessels))
+	servedBy := make(map[uuid.UUID]string)
+	for _, v := range vessels {
+		errs := validateVessel(v)
+		for _, ref := range []struct {
+			field   string
+			routeId uuid.UUID
+		}{{"routeAID", v.RouteAID()}, {"routeBID", v.RouteBID()}} {
+			if _, ok := byId[ref.routeId]; !ok {
+				if invalid[ref.routeId] {
+					errs = append(errs, FieldErrorModel{ref.field, fmt.Sprintf("references route [%s] which is quarantined", ref.routeId)})
+				} else {
+					errs = append(errs, FieldErrorModel{ref.field, fmt.Sprintf("references route [%s] which is not defined", ref.routeId)})
+				}
+				continue
+			}
+			if other, ok := servedBy[ref.routeId]; ok {
+				errs = append(errs, FieldErrorModel{ref.field, fmt.Sprintf("references route [%s] which is already served by vessel [%s]", ref.routeId, other)})
+			}
+		}
+		if v.RouteAID() == v.RouteBID() {
+			errs = append(errs, FieldErrorModel{"routeBID", "must differ from routeAID"})
+		}
+		if len(errs) > 0 {
+			quarantined = append(quarantined, QuarantineModel{kind: QuarantinedVessel, id: v.Id(), name: v.Name(), errors: errs})
+			continue
+		}
+		servedBy[v.RouteAID()] = v.Name()
+		servedBy[v.RouteBID()] = v.Name()
+		validVessels = append(validVessels, v)
+	}
+
+	validRoutes := make([]Model, 0, len(candidates))
+	for _, r := range candidates {
+		if _, ok := servedBy[r.Id()]; !ok {
+			if errs := validateCycleInterval(r); len(errs) > 0 {
+				quarantined = append(quarantined, QuarantineModel{kind: QuarantinedRoute, id: r.Id(), name: r.Name(), errors: errs})
+				continue
+			}
+		}
+		validRoutes = append(validRoutes, r)
+	}
+	return validRoutes, validVessels, quarantined
+}
+
+// validateRoute checks the fields of a route definition which do not depend on other definitions.
+func validateRoute(r Model) []FieldErrorModel {
+	var errs []FieldErrorModel
+	if r.Id() == uuid.Nil {
+		errs = append(errs, FieldErrorModel{"id", "is required"})
+	}
+	if r.Name() == "" {
+		errs = append(errs, FieldErrorModel{"name", "is required"})
+	}
+	if len(r.EnRouteMapIds()) == 0 {
+		errs = append(errs, FieldErrorModel{"enRouteMapIds", "must contain at least one map"})
+	}
+	if r.DestinationMapId() == r.StartMapId() {
+		errs = append(errs, FieldErrorModel{"destinationMapId", "must differ from startMapId"})
+	}
+	if r.BoardingWindowDuration() <= 0 {
+		errs = append(errs, FieldErrorModel{"boardingWindowDuration", "must be positive"})
+	}
+	if r.PreDepartureDuration() < 0 {
+		errs = append(errs, FieldErrorModel{"preDepartureDuration", "must not be negative"})
+	}
+	if r.TravelDuration() <= 0 {
+		errs = append(errs, FieldErrorModel{"travelDuration", "must be positive"})
+	}
+	if r.BoardingWindowDuration()+r.PreDepartureDuration()+r.TravelDuration() >= 24*time.Hour {
+		errs = append(errs, FieldErrorModel{"travelDuration", "must allow a trip, from boarding open to arrival, to complete within a day"})
+	}
+	errs = append(errs, validateChannelStagger(r)...)
+	errs = append(errs, validateWarpStrategy("departureWarp", r.DepartureWarp())...)
+	errs = append(errs, validateWarpStrategy("arrivalWarp", r.ArrivalWarp())...)
+	errs = append(errs, validateLogoutPolicies(r.LogoutPolicies())...)
+	return errs
+}
+
+// validateCycleInterval checks the cycle of a route not served by a shared vessel, which must be positive, and must not
+// open boarding for a trip before the last one has arrived.
+func validateCycleInterval(r Model) []FieldErrorModel {
+	if r.CycleInterval() <= 0 {
+		return []FieldErrorModel{{"cycleInterval", "must be positive for a route not served by a shared vessel"}}
+	}
+	if trip := r.BoardingWindowDuration() + r.PreDepartureDuration() + r.TravelDuration(); r.CycleInterval() < trip {
+		return []FieldErrorModel{{"cycleInterval", fmt.Sprintf("must be at least the [%s] a trip takes from boarding open to arrival", trip)}}
+	}
+	return nil
+}
+
+// validateChannelStagger checks that the last channel of a world is issued a transition before the next one is due, so
+// that no channel falls a whole phase behind. A world enabled as a whole is assumed to run maxChannelsPerWorld channels.
+func validateChannelStagger(r Model) []FieldErrorModel {
+	if r.ChannelStagger() < 0 {
+		return []FieldErrorModel{{"channelStaggerMs", "must not be negative"}}
+	}
+	if r.ChannelStagger() == 0 {
+		return nil
+	}
+	channels := 0
+	for _, e := range r.Enablement() {
+		n := len(e.ChannelIds())
+		if n == 0 {
+			n = maxChannelsPerWorld
+		}
+		channels = max(channels, n)
+	}
+	if len(r.Enablement()) == 0 {
+		channels = maxChannelsPerWorld
+	}
+	var shortest time.Duration
+	for _, d := range []time.Duration{r.BoardingWindowDuration(), r.PreDepartureDuration(), r.TravelDuration()} {
+		if d > 0 && (shortest == 0 || d < shortest) {
+			shortest = d
+		}
+	}
+	if shortest > 0 && r.ChannelOffset(channels-1) >= shortest {
+		return []FieldErrorModel{{"channelStaggerMs", fmt.Sprintf("must delay the last of %d channels by less than the shortest phase of [%s]", channels, shortest)}}
+	}
+	return nil
+}
+
+// validateLogoutPolicies checks that logout policies are keyed by known route states, and are known policies.
+func validateLogoutPolicies(ps map[RouteState]LogoutPolicy) []FieldErrorModel {
+	var errs []FieldErrorModel
+	for _, state := range sortedStates(ps) {
+		field := "logoutPolicies." + string(state)
+		if !slices.Contains(routeStates, state) {
+			errs = append(errs, FieldErrorModel{field, fmt.Sprintf("[%s] is not a route state", state)})
+			continue
+		}
+		if !slices.Contains(logoutPolicies, ps[state]) {
+			errs = append(errs, FieldErrorModel{field, fmt.Sprintf("[%s] is not a known logout policy", ps[state])})
+		}
+	}
+	return errs
+}
+
+// sortedStates returns the states of the policies in a stable order, so that errors are reported consistently.
+func sortedStates(ps map[RouteState]LogoutPolicy) []RouteState {
+	states := make([]RouteState, 0, len(ps))
+	for state := range ps {
+		states = append(states, state)
+	}
+	slices.Sort(states)
+	return states
+}
+
+// validateWarpStrategy checks that a warp strategy is of a known type, and names its portal when the type requires it.
+func validateWarpStrategy(field string, s WarpStrategy) []FieldErrorModel {
+	switch s.Type() {
+	case WarpRandomSpawn, WarpFixedPortal, WarpPreservePosition:
+		return nil
+	case WarpNamedPortal:
+		if s.PortalName() == "" {
+			return []FieldErrorModel{{field + ".portalName", "is required for a named_portal warp"}}
+		}
+		return nil
+	default:
+		return []FieldErrorModel{{field + ".type", fmt.Sprintf("[%s] is not a known warp strategy", s.Type())}}
+	}
+}
+
+// validateVessel checks the fields of a shared vessel definition which do not depend on other definitions.
+func validateVessel(v SharedVesselModel) []FieldErrorModel {
+	var errs []FieldErrorModel
+	if v.Id() == uuid.Nil {
+		errs = append(errs, FieldErrorModel{"id", "is required"})
+	}
+	if v.Name() == "" {
+		errs = append(errs, FieldErrorModel{"name", "is required"})
+	}
+	if v.TurnaroundDelay() < 0 {
+		errs = append(errs, FieldErrorModel{"turnaroundDelay", "must not be negative"})
+	}
+	return errs
+}
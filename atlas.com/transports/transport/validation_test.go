@@ -0,0 +1,110 @@
+package transport
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Chronicle20/atlas-constants/channel"
+	_map "github.com/Chronicle20/atlas-constants/map"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func validationRoute(name string, cycle time.Duration) Model {
+	return NewBuilder(name).
+		SetStartMapId(101000300).
+		SetStagingMapId(101000301).
+		SetEnRouteMapIds([]_map.Id{200090010}).
+		SetDestinationMapId(200000100).
+		SetBoardingWindowDuration(4 * time.Minute).
+		SetPreDepartureDuration(1 * time.Minute).
+		SetTravelDuration(10 * time.Minute).
+		SetCycleInterval(cycle).
+		Build()
+}
+
+func TestValidateConfiguration_QuarantinesInvalidRoutes(t *testing.T) {
+	valid := validationRoute("Ellinia to Orbis", time.Hour)
+	noCycle := validationRoute("No Cycle", 0)
+	noEnRoute := validationRoute("No En Route", time.Hour).Builder().SetEnRouteMapIds([]_map.Id{}).SetTravelDuration(0).Build()
+	// A 15 minute trip every 10 minutes would open boarding before the last trip arrived
+	overlapping := validationRoute("Overlapping", 10*time.Minute)
+
+	routes, vessels, quarantined := ValidateConfiguration([]Model{valid, noCycle, noEnRoute, overlapping}, nil)
+	assert.Equal(t, []uuid.UUID{valid.Id()}, routeIds(routes))
+	assert.Empty(t, vessels)
+	assert.Len(t, quarantined, 3)
+
+	assert.Equal(t, noEnRoute.Id(), quarantined[0].Id())
+	assert.Equal(t, QuarantinedRoute, quarantined[0].Kind())
+	assert.Equal(t, []FieldErrorModel{{"enRouteMapIds", "must contain at least one map"}, {"travelDuration", "must be positive"}}, quarantined[0].Errors())
+	assert.Equal(t, noCycle.Id(), quarantined[1].Id())
+	assert.Equal(t, "cycleInterval", quarantined[1].Errors()[0].Field())
+	assert.Equal(t, overlapping.Id(), quarantined[2].Id())
+	assert.Equal(t, "cycleInterval", quarantined[2].Errors()[0].Field())
+	assert.Contains(t, quarantined[2].Errors()[0].Message(), "[15m0s]")
+}
+
+func TestValidateConfiguration_QuarantinesInvalidVessels(t *testing.T) {
+	a := validationRoute("Outbound", 0)
+	b := validationRoute("Inbound", 0)
+	invalid := validationRoute("", 0)
+	vessel := NewSharedVesselBuilder().SetName("Ferry").SetRouteAID(a.Id()).SetRouteBID(b.Id()).Build()
+	rival := NewSharedVesselBuilder().SetName("Rival").SetRouteAID(a.Id()).SetRouteBID(invalid.Id()).Build()
+	unknown := NewSharedVesselBuilder().SetName("Ghost").SetRouteAID(uuid.New()).SetRouteBID(uuid.New()).Build()
+
+	routes, vessels, quarantined := ValidateConfiguration([]Model{a, b, invalid}, []SharedVesselModel{vessel, rival, unknown})
+	assert.Equal(t, []uuid.UUID{a.Id(), b.Id()}, routeIds(routes))
+	assert.Equal(t, []SharedVesselModel{vessel}, vessels)
+	assert.Len(t, quarantined, 3)
+
+	assert.Equal(t, QuarantinedVessel, quarantined[1].Kind())
+	assert.Equal(t, "Rival", quarantined[1].Name())
+	assert.Len(t, quarantined[1].Errors(), 2)
+	assert.Contains(t, quarantined[1].Errors()[0].Message(), "already served by vessel [Ferry]")
+	assert.Contains(t, quarantined[1].Errors()[1].Message(), "quarantined")
+	assert.Contains(t, quarantined[2].Errors()[0].Message(), "not defined")
+}
+
+func TestValidateConfiguration_QuarantinesUnknownWarpStrategies(t *testing.T) {
+	named := validationRoute("Named", time.Hour).Builder().SetArrivalWarp(NewWarpStrategy(WarpNamedPortal, "in00", 0)).Build()
+	unnamed := validationRoute("Unnamed", time.Hour).Builder().SetArrivalWarp(NewWarpStrategy(WarpNamedPortal, "", 0)).Build()
+	unknown := validationRoute("Unknown", time.Hour).Builder().SetDepartureWarp(NewWarpStrategy("teleport", "", 0)).Build()
+
+	routes, _, quarantined := ValidateConfiguration([]Model{named, unnamed, unknown}, nil)
+	assert.Equal(t, []uuid.UUID{named.Id()}, routeIds(routes))
+	assert.Len(t, quarantined, 2)
+	assert.Equal(t, []FieldErrorModel{{"arrivalWarp.portalName", "is required for a named_portal warp"}}, quarantined[0].Errors())
+	assert.Equal(t, []FieldErrorModel{{"departureWarp.type", "[teleport] is not a known warp strategy"}}, quarantined[1].Errors())
+}
+
+func TestValidateConfiguration_QuarantinesUnknownLogoutPolicies(t *testing.T) {
+	valid := validationRoute("Valid", time.Hour).Builder().SetLogoutPolicy(InTransit, LogoutPendingRelocation).Build()
+	unknownPolicy := validationRoute("Unknown Policy", time.Hour).Builder().SetLogoutPolicy(OpenEntry, "teleport_home").Build()
+	unknownState := validationRoute("Unknown State", time.Hour).Builder().SetLogoutPolicy("docked", LogoutStay).Build()
+
+	routes, _, quarantined := ValidateConfiguration([]Model{valid, unknownPolicy, unknownState}, nil)
+	assert.Equal(t, []uuid.UUID{valid.Id()}, routeIds(routes))
+	assert.Len(t, quarantined, 2)
+	assert.Equal(t, []FieldErrorModel{{"logoutPolicies.open_entry", "[teleport_home] is not a known logout policy"}}, quarantined[0].Errors())
+	assert.Equal(t, []FieldErrorModel{{"logoutPolicies.docked", "[docked] is not a route state"}}, quarantined[1].Errors())
+}
+
+func TestValidateConfiguration_QuarantinesChannelStaggerLongerThanAPhase(t *testing.T) {
+	// The last of three channels is delayed 50 seconds, within the one minute pre-departure phase
+	valid := validationRoute("Staggered", time.Hour).Builder().
+		SetChannelStagger(25 * time.Second).
+		SetEnablement([]WorldEnablementModel{NewWorldEnablementModel(0, []channel.Id{0, 1, 2})}).
+		Build()
+	// A whole world is assumed to run 20 channels, so the last is delayed past the pre-departure phase
+	wholeWorld := valid.Builder().SetId(uuid.New()).SetEnablement(nil).Build()
+	negative := valid.Builder().SetId(uuid.New()).SetChannelStagger(-time.Second).Build()
+
+	routes, _, quarantined := ValidateConfiguration([]Model{valid, wholeWorld, negative}, nil)
+	assert.Equal(t, []uuid.UUID{valid.Id()}, routeIds(routes))
+	assert.Len(t, quarantined, 2)
+	assert.Equal(t, wholeWorld.Id(), quarantined[0].Id())
+	assert.Equal(t, "channelStaggerMs", quarantined[0].Errors()[0].Field())
+	assert.Contains(t, quarantined[0].Errors()[0].Message(), "20 channels")
+	assert.Equal(t, "channelStaggerMs", quarantined[1].Errors()[0].Field())
+}
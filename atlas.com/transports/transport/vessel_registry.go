@@ -0,0 +1,37 @@
+package transport
+
+import (
+	"sync"
+
+	tenant "github.com/Chronicle20/atlas-tenant"
+	"github.com/google/uuid"
+)
+
+type VesselRegistry struct {
+	mutex          sync.RWMutex
+	vesselRegister map[uuid.UUID][]SharedVesselModel
+}
+
+var vesselRegistry *VesselRegistry
+var vesselRegistryOnce sync.Once
+
+func getVesselRegistry() *VesselRegistry {
+	vesselRegistryOnce.Do(func() {
+		vesselRegistry = &VesselRegistry{}
+		vesselRegistry.vesselRegister = make(map[uuid.UUID][]SharedVesselModel)
+	})
+	return vesselRegistry
+}
+
+// AddTenant replaces the shared vessels of the tenant.
+func (r *VesselRegistry) AddTenant(t tenant.Model, vessels []SharedVesselModel) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.vesselRegister[t.Id()] = append([]SharedVesselModel{}, vessels...)
+}
+
+func (r *VesselRegistry) GetVessels(t tenant.Model) []SharedVesselModel {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return append([]SharedVesselModel{}, r.vesselRegister[t.Id()]...)
+}
@@ -0,0 +1,133 @@
+package transport
+
+import (
+	"atlas-transports/character"
+	"fmt"
+	"time"
+
+	"github.com/Chronicle20/atlas-constants/field"
+	"github.com/Chronicle20/atlas-model/model"
+	"github.com/google/uuid"
+)
+
+// Leg identifies which movement of a trip a warp belongs to.
+type Leg string
+
+const (
+	// LegDeparture moves passengers from the staging map into the en-route map
+	LegDeparture Leg = "departure"
+
+	// LegArrival moves passengers from the en-route map to the destination map
+	LegArrival Leg = "arrival"
+
+	// LegRecovery moves passengers stranded in a route map which the route state says should be empty
+	LegRecovery Leg = "recovery"
+)
+
+// TripOccurrenceId identifies a single daily occurrence of a scheduled trip, by the UTC day it departed on.
+func TripOccurrenceId(tripId uuid.UUID, departedAt time.Time) string {
+	return fmt.Sprintf("%s@%s", tripId.String(), departedAt.UTC().Format("20060102"))
+}
+
+// WarpKey is the deterministic idempotency key of a warp issued for a character on one leg of a trip occurrence. Keys
+// are remembered for a day of wall clock time, so when a tenant's clock is moved backwards over a trip occurrence it
+// already issued, the warps of characters already moved on that leg are suppressed as duplicates rather than reissued.
+func WarpKey(tripOccurrenceId string, characterId uint32, leg Leg) string {
+	return fmt.Sprintf("%s:%d:%s", tripOccurrenceId, characterId, leg)
+}
+
+// occurrenceOnOrBefore resolves the time of day of t to the latest instant on or before now. Schedules are laid out in
+// UTC, so both times are normalized to UTC, and the result does not depend on the location either carries.
+func occurrenceOnOrBefore(t time.Time, now time.Time) time.Time {
+	o := onDayOf(t, now)
+	if o.After(now) {
+		o = o.AddDate(0, 0, -1)
+	}
+	return o
+}
+
+// occurrenceAfter resolves the time of day of t to the earliest instant after now, in UTC.
+func occurrenceAfter(t time.Time, now time.Time) time.Time {
+	o := onDayOf(t, now)
+	if !o.After(now) {
+		o = o.AddDate(0, 0, 1)
+	}
+	return o
+}
+
+// onDayOf returns the UTC time of day of t on the UTC day of now.
+func onDayOf(t time.Time, now time.Time) time.Time {
+	t = t.UTC()
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// movement pairs the field passengers are taken from with the field they are warped to.
+type movement struct {
+	from field.Model
+	to   field.Model
+}
+
+// passenger is a character to be warped along a movement. The position is only resolved for passengers landing where
+// they stand, and is requested on its own when unset.
+type passenger struct {
+	characterId uint32
+	movement    movement
+	position    model.Provider[character.Model]
+}
+
+// WarpStrategyType determines how the portal a passenger arrives at is chosen.
+type WarpStrategyType string
+
+const (
+	// WarpRandomSpawn lands passengers on a random spawn point of the target map
+	WarpRandomSpawn WarpStrategyType = "random_spawn"
+
+	// WarpNamedPortal lands passengers on the portal of the target map with the configured name
+	WarpNamedPortal WarpStrategyType = "named_portal"
+
+	// WarpFixedPortal lands passengers on the portal of the target map with the configured id
+	WarpFixedPortal WarpStrategyType = "fixed_portal"
+
+	// WarpPreservePosition lands passengers on the spawn point of the target map closest to their current position
+	WarpPreservePosition WarpStrategyType = "preserve_position"
+)
+
+// WarpStrategy is the configured way of choosing the target portal of a leg.
+type WarpStrategy struct {
+	strategyType WarpStrategyType
+	portalName   string
+	portalId     uint32
+}
+
+// NewWarpStrategy creates a warp strategy. An empty type defaults to WarpRandomSpawn.
+func NewWarpStrategy(strategyType WarpStrategyType, portalName string, portalId uint32) WarpStrategy {
+	if strategyType == "" {
+		strategyType = WarpRandomSpawn
+	}
+	return WarpStrategy{
+		strategyType: strategyType,
+		portalName:   portalName,
+		portalId:     portalId,
+	}
+}
+
+// RandomSpawnWarpStrategy is the default warp strategy.
+func RandomSpawnWarpStrategy() WarpStrategy {
+	return NewWarpStrategy(WarpRandomSpawn, "", 0)
+}
+
+// Type returns the strategy type
+func (s WarpStrategy) Type() WarpStrategyType {
+	return s.strategyType
+}
+
+// PortalName returns the target portal name of a WarpNamedPortal strategy
+func (s WarpStrategy) PortalName() string {
+	return s.portalName
+}
+
+// PortalId returns the target portal id of a WarpFixedPortal strategy
+func (s WarpStrategy) PortalId() uint32 {
+	return s.portalId
+}